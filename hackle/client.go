@@ -1,10 +1,15 @@
 package hackle
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/config"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/evaluator/experiment"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
@@ -12,23 +17,65 @@ import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
+	"reflect"
 	"sync"
 	"time"
 )
 
 type Client interface {
 	Variation(experimentKey int64, user User) string
+	VariationCtx(ctx context.Context, experimentKey int64, user User) string
 	VariationDetail(experimentKey int64, user User) ExperimentDecision
+	VariationDetailCtx(ctx context.Context, experimentKey int64, user User) ExperimentDecision
 	IsFeatureOn(featureKey int64, user User) bool
+	IsFeatureOnCtx(ctx context.Context, featureKey int64, user User) bool
 	FeatureFlagDetail(featureKey int64, user User) FeatureFlagDecision
+	FeatureFlagDetailCtx(ctx context.Context, featureKey int64, user User) FeatureFlagDecision
+	LogExposure(experimentKey int64, user User)
+	LogFeatureFlagExposure(featureKey int64, user User)
+	Peek() PeekClient
+	AllVariations(user User, exposure bool) map[int64]ExperimentDecision
+	AllFeatureFlags(user User, exposure bool) map[int64]FeatureFlagDecision
+	WatchFeatureFlag(featureKey int64, user User, listener func(oldDecision FeatureFlagDecision, newDecision FeatureFlagDecision)) (cancel func())
 	RemoteConfig(user User) RemoteConfig
+	RemoteConfigCtx(ctx context.Context, user User) RemoteConfig
+	AllRemoteConfigs(user User, exposure bool) map[string]RemoteConfigDecision
 	Track(event Event, user User)
+	TrackCtx(ctx context.Context, event Event, user User)
+	TrackMany(events []EventWithUser) TrackResult
+	TrackAll(user User, events ...Event) TrackResult
+	SetOverride(experimentKey int64, identifier string, variationKey string)
+	SetFeatureFlagOverride(featureKey int64, identifier string, isOn bool)
+	RemoveOverride(experimentKey int64, identifier string)
+	RemoveFeatureFlagOverride(featureKey int64, identifier string)
+	ClearOverrides()
+	ValidateEvent(event Event) []Problem
+	InvalidEventCount() int64
+	Ready() <-chan struct{}
+	WaitUntilReady(ctx context.Context) error
+	OnWorkspaceUpdate(listener func(change WorkspaceChange))
+	DroppedEventCount() int64
+	InFlightDispatchCount() int
+	QueuedDispatchCount() int
+	Flush(ctx context.Context) error
 	Close()
+	CloseWithContext(ctx context.Context) error
 }
 
+// ResponseError is returned when the Hackle server rejects a request, e.g. for an invalid sdk key.
+type ResponseError = http.ResponseError
+
 var clients = make(map[string]Client)
 var mu = &sync.Mutex{}
 
+// NewClientWithContext returns the client once its workspace is ready.
+// If the initial workspace fetch failed, it returns without waiting for ctx.
+// On failure or timeout the client is still returned and serves default decisions until ready.
+func NewClientWithContext(ctx context.Context, sdkKey string, config *Config) (Client, error) {
+	c := NewClient(sdkKey, config)
+	return c, c.WaitUntilReady(ctx)
+}
+
 func NewClient(sdkKey string, config *Config) Client {
 	mu.Lock()
 	defer mu.Unlock()
@@ -45,80 +92,435 @@ func createClient(sdkKey string, config *Config) Client {
 	if config == nil {
 		config = NewConfigBuilder().Build()
 	}
+	if config.offlineWorkspace != nil {
+		return createOfflineClient(config)
+	}
 
 	sdk := model.NewSdk(sdkKey)
 	scheduler := schedule.NewTickerScheduler()
-	httpClient := http.NewClient(sdk, clock.System, 10*time.Second)
+	httpClient := http.NewClient(sdk, clock.System, config.newHttpClient())
 
-	httpWorkspaceFetcher := workspace.NewHttpFetcher(config.sdkUrl, sdk, httpClient)
-	workspaceFetcher := workspace.NewPollingFetcher(httpWorkspaceFetcher, 10*time.Second, scheduler)
+	workspaceSnapshot := workspace.NewNoopSnapshot()
+	if config.snapshotFile != "" {
+		workspaceSnapshot = workspace.NewFileSnapshot(config.snapshotFile)
+	}
+	httpWorkspaceFetcher := workspace.NewHttpFetcher(config.sdkUrl, sdk, httpClient, workspaceSnapshot)
+	pollingWorkspaceFetcher := workspace.NewPollingFetcher(
+		httpWorkspaceFetcher,
+		workspaceSnapshot,
+		config.pollingInterval,
+		backoff.Jitter(config.pollingInterval, 0.5),
+		scheduler,
+		backoff.WithJitter(backoff.NewExponential(config.pollingInterval, 30*config.pollingInterval, 2), 0.5),
+		clock.System,
+	)
+	var workspaceFetcher workspace.RemoteFetcher = pollingWorkspaceFetcher
+	if config.workspaceStreaming {
+		streamingHttpClient := http.NewClient(sdk, clock.System, config.newStreamingHttpClient())
+		reconnectBackoff := backoff.NewExponential(time.Second, time.Minute, 2)
+		workspaceFetcher = workspace.NewStreamingFetcher(config.sdkUrl, sdk, streamingHttpClient, pollingWorkspaceFetcher, reconnectBackoff, time.Minute)
+	}
 
-	eventDispatcher := event.NewDispatcher(config.eventUrl, httpClient)
-	eventProcessor := event.NewProcessor(10000, eventDispatcher, 100, scheduler, 10*time.Second)
+	eventDispatcher := config.withEventSinks(event.NewDispatcher(
+		config.eventUrl,
+		httpClient,
+		config.eventDispatchConcurrency,
+		config.eventDispatchMaxQueuedBatches,
+		config.eventCompression,
+		config.eventMaxPayloadBytes,
+		config.eventRetryPolicy(),
+		config.eventDeadLetterHandler,
+	))
+	eventProcessor := event.NewProcessor(config.eventQueueCapacity, config.eventOverflowPolicy, config.eventOverflowBlockTimeout, eventDispatcher, config.eventDispatchSize, scheduler, config.eventFlushInterval, config.newEventStore())
+	eventProcessor = config.withEventProcessing(eventProcessor)
 
-	c := core.New(workspaceFetcher, eventProcessor)
+	localOverrides := config.newLocalOverrideStore()
+	c := core.New(workspaceFetcher, eventProcessor, config.trackValidationMode, config.decisionTrace, localOverrides)
 	userResolver := user.NewResolver()
 
 	workspaceFetcher.Start()
 	eventProcessor.Start()
 
 	return &client{
-		core:         c,
-		userResolver: userResolver,
+		core:            c,
+		eventProcessor:  eventProcessor,
+		eventDispatcher: eventDispatcher,
+		userResolver:    userResolver,
+		localOverrides:  localOverrides,
+		readiness:       workspaceFetcher,
+		notifier:        workspaceFetcher,
+		watcher:         newWatcher(workspaceFetcher),
 	}
 }
 
+func createOfflineClient(config *Config) Client {
+	ws, err := loadWorkspace(config.offlineWorkspace)
+	if err != nil {
+		logger.Error("Failed to load offline workspace: %v", err)
+	}
+
+	workspaceFetcher := workspace.NewStaticFetcher(ws)
+	eventProcessor := config.withEventListeners(event.NewNoopProcessor())
+	localOverrides := config.newLocalOverrideStore()
+
+	return &client{
+		core:           core.New(workspaceFetcher, eventProcessor, config.trackValidationMode, config.decisionTrace, localOverrides),
+		eventProcessor: eventProcessor,
+		userResolver:   user.NewResolver(),
+		localOverrides: localOverrides,
+		readiness:      workspaceFetcher,
+		notifier:       workspaceFetcher,
+		watcher:        newWatcher(workspaceFetcher),
+	}
+}
+
+func loadWorkspace(source workspaceSource) (workspace.Workspace, error) {
+	reader, err := source()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		e := reader.Close()
+		if e != nil {
+			logger.Warn("failed to close workspace source: %v", e)
+		}
+	}()
+	return workspace.Load(reader)
+}
+
 type client struct {
-	core         core.Core
-	userResolver user.Resolver
+	core            core.Core
+	eventProcessor  event.Processor
+	eventDispatcher event.Dispatcher
+	userResolver    user.Resolver
+	localOverrides  *experiment.LocalOverrideStore
+	readiness       workspace.Readiness
+	notifier        workspace.Notifier
+	watcher         *watcher
 }
 
 func (c *client) Variation(experimentKey int64, user User) string {
-	return c.VariationDetail(experimentKey, user).Variation()
+	return c.VariationCtx(context.Background(), experimentKey, user)
+}
+
+func (c *client) VariationCtx(ctx context.Context, experimentKey int64, user User) string {
+	return c.VariationDetailCtx(ctx, experimentKey, user).Variation()
 }
 
 func (c *client) VariationDetail(experimentKey int64, user User) ExperimentDecision {
-	hackleUser, ok := c.userResolver.Resolve(user)
+	return c.VariationDetailCtx(context.Background(), experimentKey, user)
+}
+
+func (c *client) VariationDetailCtx(ctx context.Context, experimentKey int64, user User) ExperimentDecision {
+	return c.variationDetail(ctx, experimentKey, user, true)
+}
+
+func (c *client) variationDetail(ctx context.Context, experimentKey int64, user User, exposure bool) ExperimentDecision {
+	hackleUser, ok := resolveUser(ctx, c.userResolver, user)
 	if !ok {
 		return decision.NewExperimentDecision("A", decision.ReasonInvalidInput, config.Empty())
 	}
-	d, err := c.core.Experiment(experimentKey, hackleUser, "A")
+	d, err := c.core.Experiment(ctx, experimentKey, hackleUser, "A", exposure)
 	if err != nil {
-		logger.Error("Unexpected error while deciding variation for experiment[%d]. Returning control variation[A]: %v", experimentKey, err)
+		logger.Error("Unexpected error while deciding variation for experiment[%d]. Returning control variation[A]: %v", experimentKey, withContextErr(ctx, err))
 		return decision.NewExperimentDecision("A", decision.ReasonException, config.Empty())
 	}
 	return d
 }
 
 func (c *client) IsFeatureOn(featureKey int64, user User) bool {
-	return c.FeatureFlagDetail(featureKey, user).IsOn()
+	return c.IsFeatureOnCtx(context.Background(), featureKey, user)
+}
+
+func (c *client) IsFeatureOnCtx(ctx context.Context, featureKey int64, user User) bool {
+	return c.FeatureFlagDetailCtx(ctx, featureKey, user).IsOn()
 }
 
 func (c *client) FeatureFlagDetail(featureKey int64, user User) FeatureFlagDecision {
-	hackleUser, ok := c.userResolver.Resolve(user)
+	return c.FeatureFlagDetailCtx(context.Background(), featureKey, user)
+}
+
+func (c *client) FeatureFlagDetailCtx(ctx context.Context, featureKey int64, user User) FeatureFlagDecision {
+	return c.featureFlagDetail(ctx, featureKey, user, true)
+}
+
+func (c *client) featureFlagDetail(ctx context.Context, featureKey int64, user User, exposure bool) FeatureFlagDecision {
+	hackleUser, ok := resolveUser(ctx, c.userResolver, user)
 	if !ok {
 		return decision.NewFeatureFlagDecision(false, decision.ReasonInvalidInput, config.Empty())
 	}
-	d, err := c.core.FeatureFlag(featureKey, hackleUser)
+	d, err := c.core.FeatureFlag(ctx, featureKey, hackleUser, exposure)
 	if err != nil {
-		logger.Error("Unexpected error while deciding feature flag[%d]. Returning control flag[false]: %v", featureKey, err)
+		logger.Error("Unexpected error while deciding feature flag[%d]. Returning control flag[false]: %v", featureKey, withContextErr(ctx, err))
 		return decision.NewFeatureFlagDecision(false, decision.ReasonException, config.Empty())
 	}
 	return d
 }
 
+// LogExposure records the exposure of the experiment that a decision without exposure, such as one
+// made through Peek, has skipped. The variation is decided again at the time of the call.
+func (c *client) LogExposure(experimentKey int64, user User) {
+	c.variationDetail(context.Background(), experimentKey, user, true)
+}
+
+func (c *client) LogFeatureFlagExposure(featureKey int64, user User) {
+	c.featureFlagDetail(context.Background(), featureKey, user, true)
+}
+
+func (c *client) Peek() PeekClient {
+	return &peekClient{client: c}
+}
+
+// AllVariations decides every experiment in the current workspace for the user.
+// Exposure events are only emitted when exposure is true.
+func (c *client) AllVariations(user User, exposure bool) map[int64]ExperimentDecision {
+	decisions := make(map[int64]ExperimentDecision)
+	hackleUser, ok := c.userResolver.Resolve(user)
+	if !ok {
+		return decisions
+	}
+	for key, d := range c.core.AllExperiments(context.Background(), hackleUser, exposure) {
+		decisions[key] = d
+	}
+	return decisions
+}
+
+func (c *client) AllFeatureFlags(user User, exposure bool) map[int64]FeatureFlagDecision {
+	decisions := make(map[int64]FeatureFlagDecision)
+	hackleUser, ok := c.userResolver.Resolve(user)
+	if !ok {
+		return decisions
+	}
+	for key, d := range c.core.AllFeatureFlags(context.Background(), hackleUser, exposure) {
+		decisions[key] = d
+	}
+	return decisions
+}
+
+// WatchFeatureFlag re-evaluates the flag without exposures, since the user has not seen anything.
+func (c *client) WatchFeatureFlag(featureKey int64, user User, listener func(oldDecision FeatureFlagDecision, newDecision FeatureFlagDecision)) (cancel func()) {
+	ctx := context.Background()
+	current := c.featureFlagDetail(ctx, featureKey, user, false)
+	var mu sync.Mutex
+	return c.watcher.watch(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		d := c.featureFlagDetail(ctx, featureKey, user, false)
+		if isSameFeatureFlagDecision(current, d) {
+			return
+		}
+		old := current
+		current = d
+		listener(old, d)
+	})
+}
+
+func isSameFeatureFlagDecision(a FeatureFlagDecision, b FeatureFlagDecision) bool {
+	if a.IsOn() != b.IsOn() {
+		return false
+	}
+	da, ok := a.(decision.FeatureFlagDecision)
+	if !ok {
+		return false
+	}
+	db, ok := b.(decision.FeatureFlagDecision)
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(da.Config, db.Config)
+}
+
 func (c *client) RemoteConfig(user User) RemoteConfig {
-	return newRemoteConfig(user, c.userResolver, c.core)
+	return c.RemoteConfigCtx(context.Background(), user)
 }
 
-func (c *client) Track(event Event, user User) {
+func (c *client) RemoteConfigCtx(ctx context.Context, user User) RemoteConfig {
+	return newRemoteConfig(ctx, user, c.userResolver, c.core, c.watcher, true)
+}
+
+func (c *client) AllRemoteConfigs(user User, exposure bool) map[string]RemoteConfigDecision {
+	decisions := make(map[string]RemoteConfigDecision)
 	hackleUser, ok := c.userResolver.Resolve(user)
+	if !ok {
+		return decisions
+	}
+	for key, d := range c.core.AllRemoteConfigs(context.Background(), hackleUser, exposure) {
+		decisions[key] = d
+	}
+	return decisions
+}
+
+func (c *client) Track(event Event, user User) {
+	c.TrackCtx(context.Background(), event, user)
+}
+
+func (c *client) TrackCtx(ctx context.Context, event Event, user User) {
+	hackleUser, ok := resolveUser(ctx, c.userResolver, user)
 	if !ok {
 		return
 	}
-	c.core.Track(event, hackleUser)
+	c.core.Track(ctx, event, hackleUser)
+}
+
+func (c *client) TrackMany(events []EventWithUser) TrackResult {
+	requests := make([]core.TrackRequest, 0, len(events))
+	for _, it := range events {
+		hackleUser, ok := c.userResolver.Resolve(it.User)
+		if !ok {
+			continue
+		}
+		requests = append(requests, core.TrackRequest{Event: it.Event, User: hackleUser})
+	}
+	return c.trackMany(requests, len(events))
+}
+
+func (c *client) TrackAll(user User, events ...Event) TrackResult {
+	hackleUser, ok := c.userResolver.Resolve(user)
+	if !ok {
+		return TrackResult{Dropped: len(events)}
+	}
+	requests := make([]core.TrackRequest, 0, len(events))
+	for _, it := range events {
+		requests = append(requests, core.TrackRequest{Event: it, User: hackleUser})
+	}
+	return c.trackMany(requests, len(events))
+}
+
+func (c *client) trackMany(requests []core.TrackRequest, total int) TrackResult {
+	accepted := 0
+	if len(requests) > 0 {
+		accepted = c.core.TrackMany(context.Background(), requests)
+	}
+	return TrackResult{Accepted: accepted, Dropped: total - accepted}
+}
+
+func withContextErr(ctx context.Context, err error) error {
+	if ctx == nil {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%v (context: %v)", err, ctxErr)
+	}
+	return err
+}
+
+// SetOverride forces the variation of the experiment for users whose identifier of the
+// experiment's identifier type matches. Local overrides are evaluated before the overrides
+// configured on the dashboard and decided with the LOCAL_OVERRIDDEN reason.
+func (c *client) SetOverride(experimentKey int64, identifier string, variationKey string) {
+	c.localOverrides.Set(model.ExperimentTypeAbTest, experimentKey, identifier, variationKey)
+}
+
+func (c *client) SetFeatureFlagOverride(featureKey int64, identifier string, isOn bool) {
+	c.localOverrides.Set(model.ExperimentTypeFeatureFlag, featureKey, identifier, featureFlagVariationKey(isOn))
+}
+
+func (c *client) RemoveOverride(experimentKey int64, identifier string) {
+	c.localOverrides.Remove(model.ExperimentTypeAbTest, experimentKey, identifier)
+}
+
+func (c *client) RemoveFeatureFlagOverride(featureKey int64, identifier string) {
+	c.localOverrides.Remove(model.ExperimentTypeFeatureFlag, featureKey, identifier)
+}
+
+func (c *client) ClearOverrides() {
+	c.localOverrides.Clear()
+}
+
+func (c *client) ValidateEvent(event Event) []Problem {
+	return c.core.ValidateEvent(event)
+}
+
+func (c *client) InvalidEventCount() int64 {
+	return c.core.InvalidEventCount()
+}
+
+// Ready is closed once a workspace is loaded. With WorkspaceSnapshotFile, this may be the snapshot
+// while Hackle is still unreachable. Use the IsStale() of decisions to tell the snapshot apart.
+func (c *client) Ready() <-chan struct{} {
+	return c.readiness.Ready()
+}
+
+// WaitUntilReady returns immediately with the error of the last workspace fetch
+// if no workspace is loaded and the fetch failed.
+func (c *client) WaitUntilReady(ctx context.Context) error {
+	select {
+	case <-c.readiness.Ready():
+		return nil
+	default:
+	}
+	if err := c.readiness.Err(); err != nil {
+		return fmt.Errorf("client not ready: %w", err)
+	}
+	select {
+	case <-c.readiness.Ready():
+		return nil
+	case <-ctx.Done():
+		if err := c.readiness.Err(); err != nil {
+			return &notReadyError{ctxErr: ctx.Err(), err: err}
+		}
+		return fmt.Errorf("client not ready: %w", ctx.Err())
+	}
+}
+
+// notReadyError matches both the context error and the workspace fetch error with errors.Is and errors.As.
+type notReadyError struct {
+	ctxErr error
+	err    error
+}
+
+func (e *notReadyError) Error() string {
+	return fmt.Sprintf("client not ready: %v: %v", e.ctxErr, e.err)
+}
+
+func (e *notReadyError) Is(target error) bool {
+	return errors.Is(e.ctxErr, target)
+}
+
+func (e *notReadyError) Unwrap() error {
+	return e.err
+}
+
+// OnWorkspaceUpdate calls the listener on the workspace fetching goroutine, so the listener must not block.
+// A slow listener delays the next workspace fetch.
+func (c *client) OnWorkspaceUpdate(listener func(change WorkspaceChange)) {
+	c.notifier.AddUpdateListener(func(oldWorkspace workspace.Workspace, newWorkspace workspace.Workspace, change workspace.Change) {
+		if !change.IsEmpty() {
+			listener(change)
+		}
+	})
+}
+
+func (c *client) DroppedEventCount() int64 {
+	if c.eventDispatcher == nil {
+		return c.eventProcessor.DroppedCount()
+	}
+	return c.eventProcessor.DroppedCount() + c.eventDispatcher.DroppedCount()
+}
+
+func (c *client) InFlightDispatchCount() int {
+	if c.eventDispatcher == nil {
+		return 0
+	}
+	return c.eventDispatcher.InFlightCount()
+}
+
+func (c *client) QueuedDispatchCount() int {
+	if c.eventDispatcher == nil {
+		return 0
+	}
+	return c.eventDispatcher.QueuedCount()
+}
+
+func (c *client) Flush(ctx context.Context) error {
+	return c.eventProcessor.Flush(ctx)
 }
 
 func (c *client) Close() {
 	c.core.Close()
 }
+
+// CloseWithContext returns an *EventsAbandonedError if events are still being dispatched when ctx is done.
+func (c *client) CloseWithContext(ctx context.Context) error {
+	return c.core.CloseWithContext(ctx)
+}
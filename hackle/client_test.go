@@ -1,15 +1,25 @@
 package hackle
 
 import (
+	"context"
 	"errors"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/config"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/mocks"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -20,7 +30,7 @@ func TestNewClient(t *testing.T) {
 			EventUrl("event_url").
 			MonitoringUrl("monitoring_url").
 			Build()
-		assert.IsType(t, &client{}, NewClient("SDK_KEY", cfg))
+		assert.IsType(t, &client{}, createClient("SDK_KEY", cfg))
 	})
 
 	t.Run("create once", func(t *testing.T) {
@@ -29,6 +39,7 @@ func TestNewClient(t *testing.T) {
 			EventUrl("event_url").
 			MonitoringUrl("monitoring_url").
 			Build()
+		defer removeClient("KEY")
 
 		wg := sync.WaitGroup{}
 		clients := make([]Client, 100)
@@ -46,6 +57,107 @@ func TestNewClient(t *testing.T) {
 			assert.Equal(t, clients[0], clients[i])
 		}
 	})
+
+	t.Run("offline workspace file", func(t *testing.T) {
+		cfg := NewConfigBuilder().
+			OfflineWorkspaceFile("../testdata/workspace_config.json").
+			Build()
+		sut := createClient("OFFLINE_FILE_KEY", cfg)
+		defer sut.Close()
+
+		u := NewUserBuilder().ID("user").Build()
+		assert.Equal(t, "EXPERIMENT_DRAFT", sut.VariationDetail(5, u).Reason())
+		assert.Equal(t, "FEATURE_FLAG_INACTIVE", sut.FeatureFlagDetail(1, u).Reason())
+		sut.Track(NewEvent("a"), u)
+	})
+
+	t.Run("offline workspace bytes", func(t *testing.T) {
+		data, err := ioutil.ReadFile("../testdata/workspace_config.json")
+		assert.Nil(t, err)
+
+		cfg := NewConfigBuilder().
+			OfflineWorkspaceBytes(data).
+			Build()
+		sut := createClient("OFFLINE_BYTES_KEY", cfg)
+		defer sut.Close()
+
+		u := NewUserBuilder().ID("user").Build()
+		assert.Equal(t, "EXPERIMENT_PAUSED", sut.VariationDetail(10, u).Reason())
+	})
+
+	t.Run("when failed to load offline workspace then sdk not ready", func(t *testing.T) {
+		cfg := NewConfigBuilder().
+			OfflineWorkspaceFile("invalid").
+			Build()
+		sut := createClient("OFFLINE_INVALID_KEY", cfg)
+		defer sut.Close()
+
+		u := NewUserBuilder().ID("user").Build()
+		assert.Equal(t, "SDK_NOT_READY", sut.VariationDetail(5, u).Reason())
+	})
+}
+
+func TestNewClientWithContext(t *testing.T) {
+
+	t.Run("when workspace is ready then return client", func(t *testing.T) {
+		cfg := NewConfigBuilder().
+			OfflineWorkspaceFile("../testdata/workspace_config.json").
+			Build()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		defer removeClient("READY_KEY")
+
+		sut, err := NewClientWithContext(ctx, "READY_KEY", cfg)
+		defer sut.Close()
+
+		assert.Nil(t, err)
+		assert.IsType(t, &client{}, sut)
+	})
+
+	t.Run("when offline workspace failed to load then return error without waiting", func(t *testing.T) {
+		cfg := NewConfigBuilder().
+			OfflineWorkspaceFile("invalid").
+			Build()
+		defer removeClient("NOT_READY_KEY")
+
+		sut, err := NewClientWithContext(context.Background(), "NOT_READY_KEY", cfg)
+		defer sut.Close()
+
+		assert.NotNil(t, sut)
+		assert.Equal(t, "client not ready: workspace not loaded", err.Error())
+	})
+
+	t.Run("when initial fetch failed then return error without deadline", func(t *testing.T) {
+		server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+		}))
+		defer server.Close()
+		cfg := NewConfigBuilder().
+			SdkUrl(server.URL).
+			EventUrl(server.URL).
+			Build()
+		defer removeClient("UNAUTHORIZED_KEY")
+
+		sut, err := NewClientWithContext(context.Background(), "UNAUTHORIZED_KEY", cfg)
+		defer sut.Close()
+
+		assert.NotNil(t, sut)
+		assert.Contains(t, err.Error(), "client not ready: http status code: 401")
+		var responseErr *ResponseError
+		assert.True(t, errors.As(err, &responseErr))
+		assert.Equal(t, 401, responseErr.StatusCode)
+	})
+}
+
+func removeClient(sdkKey string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(clients, sdkKey)
+}
+
+// offlineClient creates a client serving the test workspace, bypassing the client registry.
+func offlineClient(builder *ConfigBuilder) Client {
+	return createClient("OFFLINE_KEY", builder.OfflineWorkspaceFile("../testdata/workspace_config.json").Build())
 }
 
 func Test_client_Variation(t *testing.T) {
@@ -304,9 +416,185 @@ func Test_client_FeatureFlagDetail(t *testing.T) {
 	}
 }
 
+func Test_client_VariationDetailCtx(t *testing.T) {
+	var exposures []ExposureInfo
+	var tracks []TrackInfo
+	sut := offlineClient(NewConfigBuilder().
+		OnExposure(func(info ExposureInfo) { exposures = append(exposures, info) }).
+		OnTrack(func(info TrackInfo) { tracks = append(tracks, info) }))
+
+	type requestIDKey struct{}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "request")
+	ctx = ContextWithUser(ctx, NewUserBuilder().ID("context_user").Build())
+
+	variation := sut.VariationDetailCtx(ctx, 7, User{})
+	sut.TrackCtx(ctx, NewEvent("a"), NewUserBuilder().ID("explicit_user").Build())
+	sut.Close()
+
+	assert.NotEqual(t, "INVALID_INPUT", variation.Reason())
+	assert.Equal(t, 1, len(exposures))
+	assert.Equal(t, "context_user", exposures[0].UserIdentifiers["$id"])
+	assert.Equal(t, "request", exposures[0].Context.Value(requestIDKey{}))
+
+	assert.Equal(t, 1, len(tracks))
+	assert.Equal(t, "explicit_user", tracks[0].UserIdentifiers["$id"])
+	assert.Equal(t, "request", tracks[0].Context.Value(requestIDKey{}))
+}
+
+func Test_client_VariationDetail_withTrace(t *testing.T) {
+	sut := offlineClient(NewConfigBuilder().DecisionTrace(true))
+	defer sut.Close()
+
+	u := NewUserBuilder().ID("user").Build()
+	d := sut.VariationDetail(7, u)
+	steps := d.Trace().Steps
+	assert.NotEmpty(t, steps)
+	assert.Equal(t, d.Reason(), steps[len(steps)-1].Reason)
+	assert.NotNil(t, sut.FeatureFlagDetail(1, u).Trace())
+}
+
+func Test_client_AllVariations(t *testing.T) {
+	var exposures []ExposureInfo
+	sut := offlineClient(NewConfigBuilder().
+		OnExposure(func(info ExposureInfo) { exposures = append(exposures, info) }))
+
+	u := NewUserBuilder().ID("user").Build()
+	variations := sut.AllVariations(u, false)
+	featureFlags := sut.AllFeatureFlags(u, false)
+	remoteConfigs := sut.AllRemoteConfigs(u, false)
+
+	assert.Equal(t, sut.VariationDetail(7, u), variations[7])
+	assert.Equal(t, sut.FeatureFlagDetail(1, u), featureFlags[1])
+	assert.Contains(t, remoteConfigs, "json_key_1")
+	assert.Equal(t, 0, len(sut.AllVariations(User{}, true)))
+
+	sut.AllVariations(u, true)
+	sut.Close()
+	assert.Equal(t, 2+len(variations), len(exposures))
+}
+
+func Test_client_Peek(t *testing.T) {
+	var exposures []ExposureInfo
+	var remoteConfigs []RemoteConfigInfo
+	sut := offlineClient(NewConfigBuilder().
+		OnExposure(func(info ExposureInfo) { exposures = append(exposures, info) }).
+		OnRemoteConfig(func(info RemoteConfigInfo) { remoteConfigs = append(remoteConfigs, info) }))
+
+	u := NewUserBuilder().ID("user").Build()
+	peek := sut.Peek()
+	variation := peek.VariationDetail(7, u)
+	featureFlag := peek.FeatureFlagDetail(1, u)
+	peek.RemoteConfig(u).GetString("json_key_1", "default")
+
+	assert.Equal(t, sut.VariationDetail(7, u), variation)
+	assert.Equal(t, sut.FeatureFlagDetail(1, u), featureFlag)
+
+	sut.LogExposure(7, u)
+	sut.LogFeatureFlagExposure(1, u)
+	sut.Close()
+
+	assert.Equal(t, 4, len(exposures))
+	assert.Equal(t, int64(7), exposures[2].ExperimentKey)
+	assert.Equal(t, variation.Variation(), exposures[2].VariationKey)
+	assert.Equal(t, int64(1), exposures[3].ExperimentKey)
+	assert.Equal(t, 0, len(remoteConfigs))
+}
+
+func Test_client_SetOverride(t *testing.T) {
+	sut := offlineClient(NewConfigBuilder())
+	defer sut.Close()
+
+	u := NewUserBuilder().ID("user").Build()
+	sut.SetOverride(7, "user", "C")
+	sut.SetFeatureFlagOverride(1, "user", false)
+
+	variation := sut.VariationDetail(7, u)
+	assert.Equal(t, "C", variation.Variation())
+	assert.Equal(t, "LOCAL_OVERRIDDEN", variation.Reason())
+	featureFlag := sut.FeatureFlagDetail(1, u)
+	assert.Equal(t, false, featureFlag.IsOn())
+	assert.Equal(t, "LOCAL_OVERRIDDEN", featureFlag.Reason())
+	assert.NotEqual(t, "LOCAL_OVERRIDDEN", sut.VariationDetail(7, NewUserBuilder().ID("other").Build()).Reason())
+
+	sut.RemoveOverride(7, "user")
+	assert.NotEqual(t, "LOCAL_OVERRIDDEN", sut.VariationDetail(7, u).Reason())
+	assert.Equal(t, "LOCAL_OVERRIDDEN", sut.FeatureFlagDetail(1, u).Reason())
+
+	sut.ClearOverrides()
+	assert.NotEqual(t, "LOCAL_OVERRIDDEN", sut.FeatureFlagDetail(1, u).Reason())
+}
+
+func Test_client_SetOverride_fromConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "overrides*.json")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"experiments": {"7": {"user": "C"}}, "featureFlags": {"1": {"user": false}}}`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	assert.Nil(t, os.Setenv("HACKLE_TEST_OVERRIDES", `{"experiments": {"7": {"user": "B"}}}`))
+	defer os.Unsetenv("HACKLE_TEST_OVERRIDES")
+
+	sut := offlineClient(NewConfigBuilder().
+		LocalOverrideFile(file.Name()).
+		LocalOverrideEnv("HACKLE_TEST_OVERRIDES"))
+	defer sut.Close()
+
+	u := NewUserBuilder().ID("user").Build()
+	assert.Equal(t, "B", sut.VariationDetail(7, u).Variation())
+	assert.Equal(t, "LOCAL_OVERRIDDEN", sut.VariationDetail(7, u).Reason())
+	assert.Equal(t, false, sut.IsFeatureOn(1, u))
+	assert.Equal(t, "LOCAL_OVERRIDDEN", sut.FeatureFlagDetail(1, u).Reason())
+}
+
+func Test_client_WatchFeatureFlag(t *testing.T) {
+	notifier := &mockNotifier{}
+	core := &mockCore{featureFlag: decision.NewFeatureFlagDecision(false, decision.ReasonDefaultRule, config.Empty())}
+	sut := &client{core: core, userResolver: user.NewResolver(), watcher: newWatcher(notifier)}
+
+	var changes [][]bool
+	cancel := sut.WatchFeatureFlag(42, User{id: "42"}, func(oldDecision FeatureFlagDecision, newDecision FeatureFlagDecision) {
+		changes = append(changes, []bool{oldDecision.IsOn(), newDecision.IsOn()})
+	})
+
+	notifier.update(nil, nil)
+	assert.Equal(t, 0, len(changes))
+
+	core.featureFlag = decision.NewFeatureFlagDecision(true, decision.ReasonDefaultRule, config.Empty())
+	notifier.update(nil, nil)
+	assert.Equal(t, [][]bool{{false, true}}, changes)
+
+	core.featureFlag = decision.NewFeatureFlagDecision(true, decision.ReasonTargetRuleMatch, config.Empty())
+	notifier.update(nil, nil)
+	assert.Equal(t, [][]bool{{false, true}}, changes)
+
+	core.featureFlag = decision.NewFeatureFlagDecision(true, decision.ReasonTargetRuleMatch, config.New(map[string]interface{}{"a": "b"}))
+	notifier.update(nil, nil)
+	assert.Equal(t, [][]bool{{false, true}, {true, true}}, changes)
+
+	cancel()
+	core.featureFlag = decision.NewFeatureFlagDecision(false, decision.ReasonDefaultRule, config.Empty())
+	notifier.update(nil, nil)
+	assert.Equal(t, [][]bool{{false, true}, {true, true}}, changes)
+}
+
+func Test_client_Watch_withoutExposure(t *testing.T) {
+	notifier := &mockNotifier{}
+	processor := &mockEventProcessor{}
+	c := core.New(workspace.NewFileFetcher("../testdata/workspace_config.json"), processor, core.TrackValidationOff, false, nil)
+	sut := &client{core: c, userResolver: user.NewResolver(), watcher: newWatcher(notifier)}
+	u := NewUserBuilder().ID("user").Build()
+
+	sut.WatchFeatureFlag(1, u, func(oldDecision FeatureFlagDecision, newDecision FeatureFlagDecision) {})
+	sut.RemoteConfig(u).Watch("json_key_1", "default", func(oldDecision RemoteConfigDecision, newDecision RemoteConfigDecision) {})
+	notifier.update(nil, nil)
+	notifier.update(nil, nil)
+
+	assert.Equal(t, 0, len(processor.processed))
+}
+
 func Test_client_RemoteConfig(t *testing.T) {
 	t.Run("return remote config instance", func(t *testing.T) {
-		sut := &client{&mockCore{}, &mockUserResolver{}}
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}}
 
 		rc := sut.RemoteConfig(User{id: "42"})
 
@@ -318,36 +606,351 @@ func Test_client_RemoteConfig(t *testing.T) {
 func Test_client_Track(t *testing.T) {
 	t.Run("when user not resolved then do not track", func(t *testing.T) {
 		core := &mockCore{}
-		sut := &client{core, user.NewResolver()}
+		sut := &client{core: core, userResolver: user.NewResolver()}
 		sut.Track(NewEvent("test"), User{})
 		assert.Equal(t, 0, core.trackCount)
 	})
 
 	t.Run("when user resolved then track event", func(t *testing.T) {
 		core := &mockCore{}
-		sut := &client{core, user.NewResolver()}
+		sut := &client{core: core, userResolver: user.NewResolver()}
 		sut.Track(NewEvent("test"), User{id: "42"})
 		assert.Equal(t, 1, core.trackCount)
 	})
 }
 
+func Test_client_TrackMany(t *testing.T) {
+
+	t.Run("skip unresolved users", func(t *testing.T) {
+		core := &mockCore{}
+		sut := &client{core: core, userResolver: user.NewResolver()}
+		result := sut.TrackMany([]EventWithUser{
+			{Event: NewEvent("a"), User: User{id: "42"}},
+			{Event: NewEvent("b"), User: User{}},
+			{Event: NewEvent("c"), User: User{id: "43"}},
+		})
+		assert.Equal(t, TrackResult{Accepted: 2, Dropped: 1}, result)
+		assert.Equal(t, 2, core.trackCount)
+	})
+
+	t.Run("report events not accepted by core as dropped", func(t *testing.T) {
+		accepted := 1
+		sut := &client{core: &mockCore{accepted: &accepted}, userResolver: user.NewResolver()}
+		result := sut.TrackMany([]EventWithUser{
+			{Event: NewEvent("a"), User: User{id: "42"}},
+			{Event: NewEvent("b"), User: User{id: "42"}},
+		})
+		assert.Equal(t, TrackResult{Accepted: 1, Dropped: 1}, result)
+	})
+
+	t.Run("when no events then track nothing", func(t *testing.T) {
+		core := &mockCore{}
+		sut := &client{core: core, userResolver: user.NewResolver()}
+		assert.Equal(t, TrackResult{}, sut.TrackMany(nil))
+		assert.Equal(t, 0, core.trackCount)
+	})
+}
+
+func Test_client_TrackAll(t *testing.T) {
+
+	t.Run("when user not resolved then drop all events", func(t *testing.T) {
+		core := &mockCore{}
+		sut := &client{core: core, userResolver: user.NewResolver()}
+		result := sut.TrackAll(User{}, NewEvent("a"), NewEvent("b"))
+		assert.Equal(t, TrackResult{Accepted: 0, Dropped: 2}, result)
+		assert.Equal(t, 0, core.trackCount)
+	})
+
+	t.Run("track all events for the user", func(t *testing.T) {
+		core := &mockCore{}
+		sut := &client{core: core, userResolver: user.NewResolver()}
+		result := sut.TrackAll(User{id: "42"}, NewEvent("a"), NewEvent("b"))
+		assert.Equal(t, TrackResult{Accepted: 2, Dropped: 0}, result)
+		assert.Equal(t, 2, core.trackCount)
+	})
+}
+
+func Test_client_eventListeners(t *testing.T) {
+	var exposures []ExposureInfo
+	var tracks []TrackInfo
+	var remoteConfigs []RemoteConfigInfo
+	sut := offlineClient(NewConfigBuilder().
+		OnExposure(func(info ExposureInfo) { exposures = append(exposures, info) }).
+		OnTrack(func(info TrackInfo) { tracks = append(tracks, info) }).
+		OnRemoteConfig(func(info RemoteConfigInfo) { remoteConfigs = append(remoteConfigs, info) }))
+
+	u := NewUserBuilder().ID("user").Property("age", 42).Build()
+	variation := sut.VariationDetail(7, u)
+	sut.Track(NewEventBuilder("a").Value(42).Property("k", "v").Build(), u)
+	sut.RemoteConfig(u).GetString("json_key_1", "default")
+	sut.Close()
+
+	assert.Equal(t, 1, len(exposures))
+	assert.Equal(t, int64(7), exposures[0].ExperimentKey)
+	assert.Equal(t, false, exposures[0].FeatureFlag)
+	assert.Equal(t, variation.Variation(), exposures[0].VariationKey)
+	assert.Equal(t, variation.Reason(), exposures[0].Reason)
+	assert.Equal(t, "user", exposures[0].UserIdentifiers["$id"])
+	assert.Equal(t, 42, exposures[0].UserProperties["age"])
+
+	assert.Equal(t, 1, len(tracks))
+	assert.Equal(t, "a", tracks[0].EventKey)
+	assert.Equal(t, 42.0, tracks[0].Value)
+	assert.Equal(t, "v", tracks[0].Properties["k"])
+
+	assert.Equal(t, 1, len(remoteConfigs))
+	assert.Equal(t, "json_key_1", remoteConfigs[0].ParameterKey)
+}
+
+func Test_client_Track_validation(t *testing.T) {
+	sut := offlineClient(NewConfigBuilder().TrackValidation(TrackValidationReject))
+	defer sut.Close()
+
+	assert.Equal(t, 0, len(sut.ValidateEvent(NewEvent("a"))))
+	assert.Equal(t, ProblemUndefinedEventKey, sut.ValidateEvent(NewEvent("undefined"))[0].Code)
+
+	u := NewUserBuilder().ID("user").Build()
+	sut.Track(NewEvent("a"), u)
+	sut.Track(NewEvent("undefined"), u)
+	assert.Equal(t, int64(1), sut.InvalidEventCount())
+}
+
+func Test_client_ValidateEvent(t *testing.T) {
+	problems := []Problem{{Code: ProblemUndefinedEventKey, Message: "undefined"}}
+	sut := &client{core: &mockCore{problems: problems, invalidCount: 42}, userResolver: user.NewResolver()}
+	assert.Equal(t, problems, sut.ValidateEvent(NewEvent("test")))
+	assert.Equal(t, int64(42), sut.InvalidEventCount())
+}
+
 func Test_client_Close(t *testing.T) {
 	core := &mockCore{}
-	sut := &client{core, user.NewResolver()}
+	sut := &client{core: core, userResolver: user.NewResolver()}
 	assert.Equal(t, false, core.closed)
 	sut.Close()
 	assert.Equal(t, true, core.closed)
 }
 
+func Test_client_Flush(t *testing.T) {
+	sut := &client{core: &mockCore{}, eventProcessor: &mockEventProcessor{flushErr: errors.New("flush fail")}, userResolver: user.NewResolver()}
+	assert.Equal(t, errors.New("flush fail"), sut.Flush(context.Background()))
+}
+
+func Test_client_CloseWithContext(t *testing.T) {
+	core := &mockCore{closeErr: errors.New("abandoned")}
+	sut := &client{core: core, userResolver: user.NewResolver()}
+	assert.Equal(t, errors.New("abandoned"), sut.CloseWithContext(context.Background()))
+	assert.Equal(t, true, core.closed)
+}
+
+func Test_client_DroppedEventCount(t *testing.T) {
+	sut := &client{core: &mockCore{}, eventProcessor: &mockEventProcessor{dropped: 42}, userResolver: user.NewResolver()}
+	assert.Equal(t, int64(42), sut.DroppedEventCount())
+
+	sut.eventDispatcher = &mockEventDispatcher{dropped: 8}
+	assert.Equal(t, int64(50), sut.DroppedEventCount())
+}
+
+func Test_client_DispatchCount(t *testing.T) {
+	sut := &client{core: &mockCore{}, eventDispatcher: &mockEventDispatcher{inFlight: 3, queued: 7}, userResolver: user.NewResolver()}
+	assert.Equal(t, 3, sut.InFlightDispatchCount())
+	assert.Equal(t, 7, sut.QueuedDispatchCount())
+
+	offline := &client{core: &mockCore{}, userResolver: user.NewResolver()}
+	assert.Equal(t, 0, offline.InFlightDispatchCount())
+	assert.Equal(t, 0, offline.QueuedDispatchCount())
+}
+
+type mockEventDispatcher struct {
+	inFlight int
+	queued   int
+	dropped  int64
+}
+
+func (m *mockEventDispatcher) Dispatch(userEvents []event.UserEvent, done func(err error)) {}
+
+func (m *mockEventDispatcher) InFlightCount() int {
+	return m.inFlight
+}
+
+func (m *mockEventDispatcher) QueuedCount() int {
+	return m.queued
+}
+
+func (m *mockEventDispatcher) DroppedCount() int64 {
+	return m.dropped
+}
+
+func (m *mockEventDispatcher) Close() {}
+
+type mockEventProcessor struct {
+	processed []event.UserEvent
+	dropped   int64
+	flushErr  error
+}
+
+func (m *mockEventProcessor) Process(event event.UserEvent) {
+	m.processed = append(m.processed, event)
+}
+
+func (m *mockEventProcessor) ProcessAll(events []event.UserEvent) int {
+	return len(events)
+}
+
+func (m *mockEventProcessor) DroppedCount() int64 {
+	return m.dropped
+}
+
+func (m *mockEventProcessor) Flush(ctx context.Context) error {
+	return m.flushErr
+}
+
+func (m *mockEventProcessor) Start() {}
+
+func (m *mockEventProcessor) Close() {}
+
+func (m *mockEventProcessor) CloseWithContext(ctx context.Context) error {
+	return nil
+}
+
+func Test_client_WaitUntilReady(t *testing.T) {
+
+	t.Run("when ready then return nil", func(t *testing.T) {
+		readiness := &mockReadiness{ready: make(chan struct{})}
+		close(readiness.ready)
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, readiness: readiness}
+
+		assert.Nil(t, sut.WaitUntilReady(context.Background()))
+	})
+
+	t.Run("when fetch failed then return error without waiting", func(t *testing.T) {
+		readiness := &mockReadiness{ready: make(chan struct{}), err: errors.New("http status code: 500")}
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, readiness: readiness}
+
+		err := sut.WaitUntilReady(context.Background())
+
+		assert.Equal(t, "client not ready: http status code: 500", err.Error())
+	})
+
+	t.Run("when context done then return error", func(t *testing.T) {
+		readiness := &mockReadiness{ready: make(chan struct{})}
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, readiness: readiness}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sut.WaitUntilReady(ctx)
+
+		assert.Equal(t, "client not ready: context canceled", err.Error())
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("when fetch failed while waiting then match both errors", func(t *testing.T) {
+		fetchErr := &ResponseError{StatusCode: 401}
+		readiness := &mockReadiness{ready: make(chan struct{}), errs: []error{nil, fetchErr}}
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, readiness: readiness}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := sut.WaitUntilReady(ctx)
+
+		assert.Equal(t, "client not ready: context deadline exceeded: http status code: 401", err.Error())
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		var responseErr *ResponseError
+		assert.True(t, errors.As(err, &responseErr))
+		assert.Equal(t, 401, responseErr.StatusCode)
+	})
+
+	t.Run("ready channel", func(t *testing.T) {
+		readiness := &mockReadiness{ready: make(chan struct{})}
+		sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, readiness: readiness}
+
+		go close(readiness.ready)
+
+		select {
+		case <-sut.Ready():
+		case <-time.After(time.Second):
+			assert.Fail(t, "expected ready")
+		}
+	})
+}
+
+func Test_client_OnWorkspaceUpdate(t *testing.T) {
+	notifier := &mockNotifier{}
+	sut := &client{core: &mockCore{}, userResolver: &mockUserResolver{}, notifier: notifier}
+
+	var changes []WorkspaceChange
+	sut.OnWorkspaceUpdate(func(change WorkspaceChange) {
+		changes = append(changes, change)
+	})
+
+	ws := mocks.CreateWorkspace().Experiment(model.Experiment{ID: 1, Key: 42, Status: model.ExperimentStatusRunning, Version: 1})
+	notifier.update(nil, ws)
+	notifier.update(ws, ws)
+	notifier.update(ws, mocks.CreateWorkspace())
+
+	assert.Equal(t, []WorkspaceChange{
+		{
+			Experiments:            []ExperimentChange{{Type: ChangeTypeAdded, ID: 1, Key: 42, NewStatus: "RUNNING", NewVersion: 1}},
+			FeatureFlags:           []ExperimentChange{},
+			Segments:               []SegmentChange{},
+			RemoteConfigParameters: []RemoteConfigParameterChange{},
+		},
+		{
+			Experiments:            []ExperimentChange{{Type: ChangeTypeRemoved, ID: 1, Key: 42, OldStatus: "RUNNING", OldVersion: 1}},
+			FeatureFlags:           []ExperimentChange{},
+			Segments:               []SegmentChange{},
+			RemoteConfigParameters: []RemoteConfigParameterChange{},
+		},
+	}, changes)
+}
+
+type mockNotifier struct {
+	listeners []workspace.UpdateListener
+}
+
+func (m *mockNotifier) AddUpdateListener(listener workspace.UpdateListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *mockNotifier) update(oldWorkspace workspace.Workspace, newWorkspace workspace.Workspace) {
+	for _, listener := range m.listeners {
+		listener(oldWorkspace, newWorkspace, workspace.Diff(oldWorkspace, newWorkspace))
+	}
+}
+
+type mockReadiness struct {
+	ready chan struct{}
+	err   error
+	errs  []error
+}
+
+func (m *mockReadiness) Ready() <-chan struct{} {
+	return m.ready
+}
+
+func (m *mockReadiness) Err() error {
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		if len(m.errs) > 1 {
+			m.errs = m.errs[1:]
+		}
+		return err
+	}
+	return m.err
+}
+
 type mockCore struct {
 	experiment   interface{}
 	featureFlag  interface{}
 	remoteConfig interface{}
 	trackCount   int
+	accepted     *int
+	problems     []Problem
+	invalidCount int64
 	closed       bool
+	closeErr     error
 }
 
-func (m *mockCore) Experiment(experimentKey int64, user user.HackleUser, defaultVariation string) (decision.ExperimentDecision, error) {
+func (m *mockCore) Experiment(ctx context.Context, experimentKey int64, user user.HackleUser, defaultVariation string, exposure bool) (decision.ExperimentDecision, error) {
 	switch r := m.experiment.(type) {
 	case decision.ExperimentDecision:
 		return r, nil
@@ -357,7 +960,7 @@ func (m *mockCore) Experiment(experimentKey int64, user user.HackleUser, default
 	panic("implement me")
 }
 
-func (m *mockCore) FeatureFlag(featureKey int64, user user.HackleUser) (decision.FeatureFlagDecision, error) {
+func (m *mockCore) FeatureFlag(ctx context.Context, featureKey int64, user user.HackleUser, exposure bool) (decision.FeatureFlagDecision, error) {
 	switch r := m.featureFlag.(type) {
 	case decision.FeatureFlagDecision:
 		return r, nil
@@ -367,7 +970,7 @@ func (m *mockCore) FeatureFlag(featureKey int64, user user.HackleUser) (decision
 	panic("implement me")
 }
 
-func (m *mockCore) RemoteConfig(parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}) (decision.RemoteConfigDecision, error) {
+func (m *mockCore) RemoteConfig(ctx context.Context, parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}, exposure bool) (decision.RemoteConfigDecision, error) {
 	switch r := m.remoteConfig.(type) {
 	case decision.RemoteConfigDecision:
 		return r, nil
@@ -377,14 +980,47 @@ func (m *mockCore) RemoteConfig(parameterKey string, user user.HackleUser, requi
 	panic("implement me")
 }
 
-func (m *mockCore) Track(e event.HackleEvent, user user.HackleUser) {
+func (m *mockCore) AllExperiments(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.ExperimentDecision {
+	return map[int64]decision.ExperimentDecision{}
+}
+
+func (m *mockCore) AllFeatureFlags(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.FeatureFlagDecision {
+	return map[int64]decision.FeatureFlagDecision{}
+}
+
+func (m *mockCore) AllRemoteConfigs(ctx context.Context, user user.HackleUser, exposure bool) map[string]decision.RemoteConfigDecision {
+	return map[string]decision.RemoteConfigDecision{}
+}
+
+func (m *mockCore) Track(ctx context.Context, e event.HackleEvent, user user.HackleUser) {
 	m.trackCount++
 }
 
+func (m *mockCore) TrackMany(ctx context.Context, requests []core.TrackRequest) int {
+	m.trackCount += len(requests)
+	if m.accepted != nil {
+		return *m.accepted
+	}
+	return len(requests)
+}
+
+func (m *mockCore) ValidateEvent(e event.HackleEvent) []Problem {
+	return m.problems
+}
+
+func (m *mockCore) InvalidEventCount() int64 {
+	return m.invalidCount
+}
+
 func (m *mockCore) Close() {
 	m.closed = true
 }
 
+func (m *mockCore) CloseWithContext(ctx context.Context) error {
+	m.closed = true
+	return m.closeErr
+}
+
 type mockUserResolver struct {
 	returns interface{}
 }
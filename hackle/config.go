@@ -1,22 +1,191 @@
 package hackle
 
+import (
+	"bytes"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/bucketer"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	DefaultPollingInterval    = 10 * time.Second
+	DefaultEventFlushInterval = 10 * time.Second
+	DefaultEventQueueCapacity = 10000
+	DefaultEventDispatchSize  = 100
+	DefaultHttpTimeout        = 10 * time.Second
+
+	DefaultEventDispatchMaxRetries         = 3
+	DefaultEventDispatchInitialBackoff     = 1 * time.Second
+	DefaultEventDispatchMaxBackoff         = 30 * time.Second
+	DefaultEventDispatchMaxInFlightRetries = 10
+	DefaultEventStoreMaxBytes              = 64 * 1024 * 1024
+	DefaultEventOverflowBlockTimeout       = 100 * time.Millisecond
+	DefaultEventMaxPayloadBytes            = 1024 * 1024
+	DefaultEventDispatchConcurrency        = 4
+	DefaultEventDispatchMaxQueuedBatches   = 100
+	DefaultExposureDedupMaxSize            = 10000
+
+	minPollingInterval    = 1 * time.Second
+	maxPollingInterval    = 1 * time.Hour
+	minEventFlushInterval = 1 * time.Second
+	maxEventFlushInterval = 1 * time.Minute
+	minEventQueueCapacity = 100
+	maxEventQueueCapacity = 1000000
+	minEventDispatchSize  = 1
+	maxEventDispatchSize  = 1000
+	minHttpTimeout        = 100 * time.Millisecond
+	maxHttpTimeout        = 1 * time.Minute
+
+	minEventDispatchMaxRetries         = 0
+	maxEventDispatchMaxRetries         = 10
+	minEventDispatchBackoff            = 10 * time.Millisecond
+	maxEventDispatchBackoff            = 5 * time.Minute
+	minEventDispatchMaxInFlightRetries = 1
+	maxEventDispatchMaxInFlightRetries = 1000
+	minEventStoreMaxBytes              = 1024 * 1024
+	maxEventStoreMaxBytes              = 1024 * 1024 * 1024
+	minEventOverflowBlockTimeout       = 1 * time.Millisecond
+	maxEventOverflowBlockTimeout       = 1 * time.Minute
+	minEventMaxPayloadBytes            = 1024
+	maxEventMaxPayloadBytes            = 10 * 1024 * 1024
+	minEventDispatchConcurrency        = 1
+	maxEventDispatchConcurrency        = 64
+	minEventDispatchMaxQueuedBatches   = 1
+	maxEventDispatchMaxQueuedBatches   = 10000
+	minExposureDedupInterval           = 1 * time.Second
+	maxExposureDedupInterval           = 24 * time.Hour
+	minExposureDedupMaxSize            = 1
+	maxExposureDedupMaxSize            = 1000000
+)
+
+type EventOverflowPolicy = event.OverflowPolicy
+
+const (
+	EventOverflowDropNewest = event.OverflowDropNewest
+	EventOverflowDropOldest = event.OverflowDropOldest
+	EventOverflowBlock      = event.OverflowBlock
+)
+
+type TrackValidationMode = core.TrackValidationMode
+
+const (
+	TrackValidationOff    = core.TrackValidationOff
+	TrackValidationCount  = core.TrackValidationCount
+	TrackValidationWarn   = core.TrackValidationWarn
+	TrackValidationReject = core.TrackValidationReject
+)
+
 type Config struct {
-	sdkUrl        string
-	eventUrl      string
-	monitoringUrl string
+	sdkUrl             string
+	eventUrl           string
+	monitoringUrl      string
+	offlineWorkspace   workspaceSource
+	snapshotFile       string
+	workspaceStreaming bool
+	pollingInterval    time.Duration
+	eventFlushInterval time.Duration
+	eventQueueCapacity int
+	eventDispatchSize  int
+	httpTimeout        time.Duration
+	httpClient         *http.Client
+	httpTransport      http.RoundTripper
+
+	eventDispatchMaxRetries         int
+	eventDispatchInitialBackoff     time.Duration
+	eventDispatchMaxBackoff         time.Duration
+	eventDispatchMaxInFlightRetries int
+	eventDeadLetterHandler          func(userEvents []UserEvent)
+	eventStoreDirectory             string
+	eventStoreMaxBytes              int
+	eventOverflowPolicy             EventOverflowPolicy
+	eventOverflowBlockTimeout       time.Duration
+	eventCompression                bool
+	eventMaxPayloadBytes            int
+	eventDispatchConcurrency        int
+	eventDispatchMaxQueuedBatches   int
+	eventSinks                      []EventSink
+	eventListeners                  []event.Listener
+	exposureDedupInterval           time.Duration
+	exposureDedupMaxSize            int
+	trackSamplingRates              map[string]float64
+	eventDropPredicates             []func(event UserEvent) bool
+	trackValidationMode             TrackValidationMode
+	decisionTrace                   bool
+	localOverrideFile               string
+	localOverrideEnv                string
 }
 
 type ConfigBuilder struct {
-	sdkUrl        string
-	eventUrl      string
-	monitoringUrl string
+	sdkUrl             string
+	eventUrl           string
+	monitoringUrl      string
+	offlineWorkspace   workspaceSource
+	snapshotFile       string
+	workspaceStreaming bool
+	pollingInterval    time.Duration
+	eventFlushInterval time.Duration
+	eventQueueCapacity int
+	eventDispatchSize  int
+	httpTimeout        time.Duration
+	httpClient         *http.Client
+	httpTransport      http.RoundTripper
+
+	eventDispatchMaxRetries         int
+	eventDispatchInitialBackoff     time.Duration
+	eventDispatchMaxBackoff         time.Duration
+	eventDispatchMaxInFlightRetries int
+	eventDeadLetterHandler          func(userEvents []UserEvent)
+	eventStoreDirectory             string
+	eventStoreMaxBytes              int
+	eventOverflowPolicy             EventOverflowPolicy
+	eventOverflowBlockTimeout       time.Duration
+	eventCompression                bool
+	eventMaxPayloadBytes            int
+	eventDispatchConcurrency        int
+	eventDispatchMaxQueuedBatches   int
+	eventSinks                      []EventSink
+	eventListeners                  []event.Listener
+	exposureDedupInterval           time.Duration
+	exposureDedupMaxSize            int
+	trackSamplingRates              map[string]float64
+	eventDropPredicates             []func(event UserEvent) bool
+	trackValidationMode             TrackValidationMode
+	decisionTrace                   bool
+	localOverrideFile               string
+	localOverrideEnv                string
 }
 
+type workspaceSource func() (io.ReadCloser, error)
+
 func NewConfigBuilder() *ConfigBuilder {
 	return &ConfigBuilder{
-		sdkUrl:        RegionDefault.sdkUrl,
-		eventUrl:      RegionDefault.eventUrl,
-		monitoringUrl: RegionDefault.monitoringUrl,
+		sdkUrl:             RegionDefault.sdkUrl,
+		eventUrl:           RegionDefault.eventUrl,
+		monitoringUrl:      RegionDefault.monitoringUrl,
+		pollingInterval:    DefaultPollingInterval,
+		eventFlushInterval: DefaultEventFlushInterval,
+		eventQueueCapacity: DefaultEventQueueCapacity,
+		eventDispatchSize:  DefaultEventDispatchSize,
+		httpTimeout:        DefaultHttpTimeout,
+
+		eventDispatchMaxRetries:         DefaultEventDispatchMaxRetries,
+		eventDispatchInitialBackoff:     DefaultEventDispatchInitialBackoff,
+		eventDispatchMaxBackoff:         DefaultEventDispatchMaxBackoff,
+		eventDispatchMaxInFlightRetries: DefaultEventDispatchMaxInFlightRetries,
+		eventStoreMaxBytes:              DefaultEventStoreMaxBytes,
+		eventOverflowPolicy:             EventOverflowDropNewest,
+		eventOverflowBlockTimeout:       DefaultEventOverflowBlockTimeout,
+		eventMaxPayloadBytes:            DefaultEventMaxPayloadBytes,
+		eventDispatchConcurrency:        DefaultEventDispatchConcurrency,
+		eventDispatchMaxQueuedBatches:   DefaultEventDispatchMaxQueuedBatches,
+		exposureDedupMaxSize:            DefaultExposureDedupMaxSize,
 	}
 }
 
@@ -42,12 +211,390 @@ func (b *ConfigBuilder) Region(region Region) *ConfigBuilder {
 	return b
 }
 
+func (b *ConfigBuilder) OfflineWorkspaceFile(filename string) *ConfigBuilder {
+	b.offlineWorkspace = func() (io.ReadCloser, error) {
+		return os.Open(filename)
+	}
+	return b
+}
+
+func (b *ConfigBuilder) OfflineWorkspaceReader(reader io.Reader) *ConfigBuilder {
+	b.offlineWorkspace = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(reader), nil
+	}
+	return b
+}
+
+func (b *ConfigBuilder) OfflineWorkspaceBytes(workspace []byte) *ConfigBuilder {
+	return b.OfflineWorkspaceReader(bytes.NewReader(workspace))
+}
+
+// WorkspaceSnapshotFile serves decisions from a local workspace snapshot until the first poll succeeds.
+// Decisions made with the snapshot report IsStale() as true, and the client is ready once the snapshot is loaded.
+func (b *ConfigBuilder) WorkspaceSnapshotFile(filename string) *ConfigBuilder {
+	b.snapshotFile = filename
+	return b
+}
+
+func (b *ConfigBuilder) WorkspaceStreaming(workspaceStreaming bool) *ConfigBuilder {
+	b.workspaceStreaming = workspaceStreaming
+	return b
+}
+
+func (b *ConfigBuilder) PollingInterval(pollingInterval time.Duration) *ConfigBuilder {
+	b.pollingInterval = pollingInterval
+	return b
+}
+
+func (b *ConfigBuilder) EventFlushInterval(eventFlushInterval time.Duration) *ConfigBuilder {
+	b.eventFlushInterval = eventFlushInterval
+	return b
+}
+
+func (b *ConfigBuilder) EventQueueCapacity(eventQueueCapacity int) *ConfigBuilder {
+	b.eventQueueCapacity = eventQueueCapacity
+	return b
+}
+
+func (b *ConfigBuilder) EventDispatchSize(eventDispatchSize int) *ConfigBuilder {
+	b.eventDispatchSize = eventDispatchSize
+	return b
+}
+
+func (b *ConfigBuilder) HttpTimeout(httpTimeout time.Duration) *ConfigBuilder {
+	b.httpTimeout = httpTimeout
+	return b
+}
+
+// HttpClient replaces the http.Client used for all requests. HttpTimeout and HttpTransport are ignored.
+func (b *ConfigBuilder) HttpClient(httpClient *http.Client) *ConfigBuilder {
+	b.httpClient = httpClient
+	return b
+}
+
+func (b *ConfigBuilder) HttpTransport(httpTransport http.RoundTripper) *ConfigBuilder {
+	b.httpTransport = httpTransport
+	return b
+}
+
+func (b *ConfigBuilder) EventDispatchMaxRetries(maxRetries int) *ConfigBuilder {
+	b.eventDispatchMaxRetries = maxRetries
+	return b
+}
+
+func (b *ConfigBuilder) EventDispatchBackoff(initialBackoff time.Duration, maxBackoff time.Duration) *ConfigBuilder {
+	b.eventDispatchInitialBackoff = initialBackoff
+	b.eventDispatchMaxBackoff = maxBackoff
+	return b
+}
+
+// EventDispatchMaxInFlightRetries limits the number of event payloads waiting to be retried.
+// Waiting payloads do not count against EventDispatchConcurrency. Failed payloads beyond the limit are dead-lettered.
+func (b *ConfigBuilder) EventDispatchMaxInFlightRetries(maxInFlightRetries int) *ConfigBuilder {
+	b.eventDispatchMaxInFlightRetries = maxInFlightRetries
+	return b
+}
+
+// EventDeadLetterHandler receives the event batches that could not be dispatched after all retries.
+func (b *ConfigBuilder) EventDeadLetterHandler(handler func(userEvents []UserEvent)) *ConfigBuilder {
+	b.eventDeadLetterHandler = handler
+	return b
+}
+
+// EventStoreDirectory persists buffered and undelivered events under the given directory
+// so that they are dispatched again after a restart.
+func (b *ConfigBuilder) EventStoreDirectory(dir string) *ConfigBuilder {
+	b.eventStoreDirectory = dir
+	return b
+}
+
+func (b *ConfigBuilder) EventStoreMaxBytes(maxBytes int) *ConfigBuilder {
+	b.eventStoreMaxBytes = maxBytes
+	return b
+}
+
+// EventOverflowPolicy decides what happens to new events when the event queue is full.
+// With EventOverflowBlock, the caller waits up to blockTimeout before the event is dropped.
+func (b *ConfigBuilder) EventOverflowPolicy(policy EventOverflowPolicy, blockTimeout time.Duration) *ConfigBuilder {
+	b.eventOverflowPolicy = policy
+	b.eventOverflowBlockTimeout = blockTimeout
+	return b
+}
+
+func (b *ConfigBuilder) EventCompression(eventCompression bool) *ConfigBuilder {
+	b.eventCompression = eventCompression
+	return b
+}
+
+// EventMaxPayloadBytes splits a batch into multiple requests when its uncompressed JSON payload exceeds maxBytes.
+func (b *ConfigBuilder) EventMaxPayloadBytes(maxBytes int) *ConfigBuilder {
+	b.eventMaxPayloadBytes = maxBytes
+	return b
+}
+
+// EventDispatchConcurrency limits the number of event batches dispatched at the same time.
+// Excess batches wait in a queue until a dispatch completes.
+func (b *ConfigBuilder) EventDispatchConcurrency(concurrency int) *ConfigBuilder {
+	b.eventDispatchConcurrency = concurrency
+	return b
+}
+
+// EventDispatchMaxQueuedBatches limits the number of event batches waiting for a dispatch.
+// When the queue is full, new batches are passed to the dead-letter handler and counted in DroppedEventCount.
+// With an event store, they are replayed on the next start unless a dead-letter handler is configured.
+func (b *ConfigBuilder) EventDispatchMaxQueuedBatches(maxBatches int) *ConfigBuilder {
+	b.eventDispatchMaxQueuedBatches = maxBatches
+	return b
+}
+
+// AddEventSink mirrors dispatched event batches to the given sink in addition to Hackle.
+// Each sink receives batches sequentially on its own goroutine and is closed when the client is closed.
+func (b *ConfigBuilder) AddEventSink(sink EventSink) *ConfigBuilder {
+	b.eventSinks = append(b.eventSinks, sink)
+	return b
+}
+
+// OnExposure is notified of every exposure event produced by the client.
+// Listeners run on a separate goroutine, so they never block evaluation. Panics are recovered and logged.
+func (b *ConfigBuilder) OnExposure(listener func(info ExposureInfo)) *ConfigBuilder {
+	b.eventListeners = append(b.eventListeners, func(userEvent event.UserEvent) {
+		if e, ok := userEvent.(event.ExposureEvent); ok {
+			listener(newExposureInfo(e))
+		}
+	})
+	return b
+}
+
+// OnTrack is notified of every track event produced by the client.
+func (b *ConfigBuilder) OnTrack(listener func(info TrackInfo)) *ConfigBuilder {
+	b.eventListeners = append(b.eventListeners, func(userEvent event.UserEvent) {
+		if e, ok := userEvent.(event.TrackEvent); ok {
+			listener(newTrackInfo(e))
+		}
+	})
+	return b
+}
+
+// OnRemoteConfig is notified of every remote config event produced by the client.
+func (b *ConfigBuilder) OnRemoteConfig(listener func(info RemoteConfigInfo)) *ConfigBuilder {
+	b.eventListeners = append(b.eventListeners, func(userEvent event.UserEvent) {
+		if e, ok := userEvent.(event.RemoteConfigEvent); ok {
+			listener(newRemoteConfigInfo(e))
+		}
+	})
+	return b
+}
+
+// ExposureDeduplication drops exposures of the same user, experiment, variation and reason
+// within the interval. Up to maxSize recent exposures are remembered. Disabled by default.
+func (b *ConfigBuilder) ExposureDeduplication(interval time.Duration, maxSize int) *ConfigBuilder {
+	b.exposureDedupInterval = interval
+	b.exposureDedupMaxSize = maxSize
+	return b
+}
+
+// SampleTrackEvent keeps only the given fraction of track events with the key.
+// Users are sampled deterministically by their id, user id or device id, and events of users without any of them are sampled independently.
+// The rate is recorded in the "$sampling_rate" event property.
+func (b *ConfigBuilder) SampleTrackEvent(eventKey string, rate float64) *ConfigBuilder {
+	if b.trackSamplingRates == nil {
+		b.trackSamplingRates = make(map[string]float64)
+	}
+	b.trackSamplingRates[eventKey] = rate
+	return b
+}
+
+// DropEvents drops every event for which the predicate returns true before it is queued.
+func (b *ConfigBuilder) DropEvents(predicate func(event UserEvent) bool) *ConfigBuilder {
+	b.eventDropPredicates = append(b.eventDropPredicates, predicate)
+	return b
+}
+
+// TrackValidation checks tracked events against the event types defined in the workspace.
+// Invalid events are counted with TrackValidationCount, also logged with TrackValidationWarn,
+// and also dropped with TrackValidationReject.
+func (b *ConfigBuilder) TrackValidation(mode TrackValidationMode) *ConfigBuilder {
+	b.trackValidationMode = mode
+	return b
+}
+
+// DecisionTrace records the evaluation flow steps of experiment and feature flag decisions,
+// returned by the Trace of the decision. Recording costs allocations on every decision.
+func (b *ConfigBuilder) DecisionTrace(enabled bool) *ConfigBuilder {
+	b.decisionTrace = enabled
+	return b
+}
+
+// LocalOverrideFile loads local overrides of the client from a JSON file when the client is created.
+func (b *ConfigBuilder) LocalOverrideFile(filename string) *ConfigBuilder {
+	b.localOverrideFile = filename
+	return b
+}
+
+// LocalOverrideEnv loads local overrides of the client from the JSON value of an environment variable
+// when the client is created. Overrides from the environment variable take precedence over the file.
+func (b *ConfigBuilder) LocalOverrideEnv(name string) *ConfigBuilder {
+	b.localOverrideEnv = name
+	return b
+}
+
 func (b *ConfigBuilder) Build() *Config {
+	eventDispatchInitialBackoff := durationInRange("eventDispatchInitialBackoff", b.eventDispatchInitialBackoff, minEventDispatchBackoff, maxEventDispatchBackoff, DefaultEventDispatchInitialBackoff)
+	eventDispatchMaxBackoff := durationInRange("eventDispatchMaxBackoff", b.eventDispatchMaxBackoff, eventDispatchInitialBackoff, maxEventDispatchBackoff, DefaultEventDispatchMaxBackoff)
+	if eventDispatchMaxBackoff < eventDispatchInitialBackoff {
+		eventDispatchMaxBackoff = eventDispatchInitialBackoff
+	}
+	exposureDedupInterval := b.exposureDedupInterval
+	if exposureDedupInterval != 0 {
+		exposureDedupInterval = durationInRange("exposureDedupInterval", exposureDedupInterval, minExposureDedupInterval, maxExposureDedupInterval, 0)
+	}
 	return &Config{
-		sdkUrl:        b.sdkUrl,
-		eventUrl:      b.eventUrl,
-		monitoringUrl: b.monitoringUrl,
+		sdkUrl:             b.sdkUrl,
+		eventUrl:           b.eventUrl,
+		monitoringUrl:      b.monitoringUrl,
+		offlineWorkspace:   b.offlineWorkspace,
+		snapshotFile:       b.snapshotFile,
+		workspaceStreaming: b.workspaceStreaming,
+		pollingInterval:    durationInRange("pollingInterval", b.pollingInterval, minPollingInterval, maxPollingInterval, DefaultPollingInterval),
+		eventFlushInterval: durationInRange("eventFlushInterval", b.eventFlushInterval, minEventFlushInterval, maxEventFlushInterval, DefaultEventFlushInterval),
+		eventQueueCapacity: intInRange("eventQueueCapacity", b.eventQueueCapacity, minEventQueueCapacity, maxEventQueueCapacity, DefaultEventQueueCapacity),
+		eventDispatchSize:  intInRange("eventDispatchSize", b.eventDispatchSize, minEventDispatchSize, maxEventDispatchSize, DefaultEventDispatchSize),
+		httpTimeout:        durationInRange("httpTimeout", b.httpTimeout, minHttpTimeout, maxHttpTimeout, DefaultHttpTimeout),
+		httpClient:         b.httpClient,
+		httpTransport:      b.httpTransport,
+
+		eventDispatchMaxRetries:         intInRange("eventDispatchMaxRetries", b.eventDispatchMaxRetries, minEventDispatchMaxRetries, maxEventDispatchMaxRetries, DefaultEventDispatchMaxRetries),
+		eventDispatchInitialBackoff:     eventDispatchInitialBackoff,
+		eventDispatchMaxBackoff:         eventDispatchMaxBackoff,
+		eventDispatchMaxInFlightRetries: intInRange("eventDispatchMaxInFlightRetries", b.eventDispatchMaxInFlightRetries, minEventDispatchMaxInFlightRetries, maxEventDispatchMaxInFlightRetries, DefaultEventDispatchMaxInFlightRetries),
+		eventDeadLetterHandler:          b.eventDeadLetterHandler,
+		eventStoreDirectory:             b.eventStoreDirectory,
+		eventStoreMaxBytes:              intInRange("eventStoreMaxBytes", b.eventStoreMaxBytes, minEventStoreMaxBytes, maxEventStoreMaxBytes, DefaultEventStoreMaxBytes),
+		eventOverflowPolicy:             b.eventOverflowPolicy,
+		eventOverflowBlockTimeout:       durationInRange("eventOverflowBlockTimeout", b.eventOverflowBlockTimeout, minEventOverflowBlockTimeout, maxEventOverflowBlockTimeout, DefaultEventOverflowBlockTimeout),
+		eventCompression:                b.eventCompression,
+		eventMaxPayloadBytes:            intInRange("eventMaxPayloadBytes", b.eventMaxPayloadBytes, minEventMaxPayloadBytes, maxEventMaxPayloadBytes, DefaultEventMaxPayloadBytes),
+		eventDispatchConcurrency:        intInRange("eventDispatchConcurrency", b.eventDispatchConcurrency, minEventDispatchConcurrency, maxEventDispatchConcurrency, DefaultEventDispatchConcurrency),
+		eventDispatchMaxQueuedBatches:   intInRange("eventDispatchMaxQueuedBatches", b.eventDispatchMaxQueuedBatches, minEventDispatchMaxQueuedBatches, maxEventDispatchMaxQueuedBatches, DefaultEventDispatchMaxQueuedBatches),
+		eventSinks:                      append([]EventSink(nil), b.eventSinks...),
+		eventListeners:                  append([]event.Listener(nil), b.eventListeners...),
+		exposureDedupInterval:           exposureDedupInterval,
+		trackSamplingRates:              samplingRates(b.trackSamplingRates),
+		trackValidationMode:             b.trackValidationMode,
+		decisionTrace:                   b.decisionTrace,
+		localOverrideFile:               b.localOverrideFile,
+		localOverrideEnv:                b.localOverrideEnv,
+		eventDropPredicates:             append([]func(event UserEvent) bool(nil), b.eventDropPredicates...),
+		exposureDedupMaxSize:            intInRange("exposureDedupMaxSize", b.exposureDedupMaxSize, minExposureDedupMaxSize, maxExposureDedupMaxSize, DefaultExposureDedupMaxSize),
+	}
+}
+
+func (c *Config) newHttpClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return &http.Client{
+		Timeout:   c.httpTimeout,
+		Transport: c.httpTransport,
+	}
+}
+
+func (c *Config) eventRetryPolicy() event.RetryPolicy {
+	return event.RetryPolicy{
+		MaxRetries:     c.eventDispatchMaxRetries,
+		InitialBackoff: c.eventDispatchInitialBackoff,
+		MaxBackoff:     c.eventDispatchMaxBackoff,
+		MaxInFlight:    c.eventDispatchMaxInFlightRetries,
+	}
+}
+
+func (c *Config) newEventStore() event.Store {
+	if c.eventStoreDirectory == "" {
+		return event.NewNoopStore()
+	}
+	return event.NewFileStore(c.eventStoreDirectory, int64(c.eventStoreMaxBytes))
+}
+
+func (c *Config) withEventSinks(dispatcher event.Dispatcher) event.Dispatcher {
+	if len(c.eventSinks) == 0 {
+		return dispatcher
+	}
+	mirrors := make([]event.Dispatcher, 0, len(c.eventSinks))
+	for _, sink := range c.eventSinks {
+		mirrors = append(mirrors, event.NewSinkDispatcher(sink, c.eventDispatchMaxQueuedBatches))
+	}
+	return event.NewFanOutDispatcher(dispatcher, mirrors...)
+}
+
+// withEventProcessing wraps the listeners inside the filters and deduplication,
+// so that listeners are notified only of events queued for dispatch.
+func (c *Config) withEventProcessing(processor event.Processor) event.Processor {
+	return c.withExposureDedup(c.withEventFilters(c.withEventListeners(processor)))
+}
+
+func (c *Config) withEventListeners(processor event.Processor) event.Processor {
+	if len(c.eventListeners) == 0 {
+		return processor
+	}
+	return event.NewListeningProcessor(processor, c.eventListeners, c.eventQueueCapacity)
+}
+
+func (c *Config) withExposureDedup(processor event.Processor) event.Processor {
+	if c.exposureDedupInterval == 0 {
+		return processor
+	}
+	return event.NewDedupProcessor(processor, event.NewExposureDeduplicator(c.exposureDedupInterval, c.exposureDedupMaxSize, clock.System))
+}
+
+func (c *Config) withEventFilters(processor event.Processor) event.Processor {
+	var filters []event.Filter
+	for _, predicate := range c.eventDropPredicates {
+		filters = append(filters, event.NewPredicateFilter(predicate))
+	}
+	if len(c.trackSamplingRates) > 0 {
+		filters = append(filters, event.NewTrackSampler(c.trackSamplingRates, bucketer.NewHasher()))
+	}
+	if len(filters) == 0 {
+		return processor
+	}
+	return event.NewFilteringProcessor(processor, filters)
+}
+
+func (c *Config) newStreamingHttpClient() *http.Client {
+	client := *c.newHttpClient()
+	client.Timeout = 0
+	return &client
+}
+
+func durationInRange(name string, value time.Duration, min time.Duration, max time.Duration, defaultValue time.Duration) time.Duration {
+	if value < min || value > max {
+		logger.Warn("Invalid %s [%s]. %s must be between %s and %s. Using default value [%s].", name, value, name, min, max, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+func samplingRates(rates map[string]float64) map[string]float64 {
+	if len(rates) == 0 {
+		return nil
+	}
+	valid := make(map[string]float64, len(rates))
+	for key, rate := range rates {
+		if rate < 0 || rate > 1 {
+			logger.Warn("Invalid sampling rate [%v] for event [%s]. Sampling rate must be between 0 and 1. Ignoring it.", rate, key)
+			continue
+		}
+		valid[key] = rate
+	}
+	return valid
+}
+
+func intInRange(name string, value int, min int, max int, defaultValue int) int {
+	if value < min || value > max {
+		logger.Warn("Invalid %s [%d]. %s must be between %d and %d. Using default value [%d].", name, value, name, min, max, defaultValue)
+		return defaultValue
 	}
+	return value
 }
 
 type Region struct {
@@ -1,20 +1,273 @@
 package hackle
 
 import (
+	"bytes"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestConfig(t *testing.T) {
 	assert.Equal(t, Config{
-		sdkUrl:        "https://static-sdk.hackle.io",
-		eventUrl:      "https://static-event.hackle.io",
-		monitoringUrl: "https://static-monitoring.hackle.io",
+		sdkUrl:             "https://static-sdk.hackle.io",
+		eventUrl:           "https://static-event.hackle.io",
+		monitoringUrl:      "https://static-monitoring.hackle.io",
+		pollingInterval:    10 * time.Second,
+		eventFlushInterval: 10 * time.Second,
+		eventQueueCapacity: 10000,
+		eventDispatchSize:  100,
+		httpTimeout:        10 * time.Second,
+
+		eventDispatchMaxRetries:         3,
+		eventDispatchInitialBackoff:     1 * time.Second,
+		eventDispatchMaxBackoff:         30 * time.Second,
+		eventDispatchMaxInFlightRetries: 10,
+		eventStoreMaxBytes:              64 * 1024 * 1024,
+		eventOverflowPolicy:             EventOverflowDropNewest,
+		eventOverflowBlockTimeout:       100 * time.Millisecond,
+		eventMaxPayloadBytes:            1024 * 1024,
+		eventDispatchConcurrency:        4,
+		eventDispatchMaxQueuedBatches:   100,
+		exposureDedupMaxSize:            10000,
 	}, *NewConfigBuilder().Region(RegionStatic).Build())
 
 	assert.Equal(t, Config{
-		sdkUrl:        "https://sdk.hackle.io",
-		eventUrl:      "https://event.hackle.io",
-		monitoringUrl: "https://monitoring.hackle.io",
+		sdkUrl:             "https://sdk.hackle.io",
+		eventUrl:           "https://event.hackle.io",
+		monitoringUrl:      "https://monitoring.hackle.io",
+		pollingInterval:    10 * time.Second,
+		eventFlushInterval: 10 * time.Second,
+		eventQueueCapacity: 10000,
+		eventDispatchSize:  100,
+		httpTimeout:        10 * time.Second,
+
+		eventDispatchMaxRetries:         3,
+		eventDispatchInitialBackoff:     1 * time.Second,
+		eventDispatchMaxBackoff:         30 * time.Second,
+		eventDispatchMaxInFlightRetries: 10,
+		eventStoreMaxBytes:              64 * 1024 * 1024,
+		eventOverflowPolicy:             EventOverflowDropNewest,
+		eventOverflowBlockTimeout:       100 * time.Millisecond,
+		eventMaxPayloadBytes:            1024 * 1024,
+		eventDispatchConcurrency:        4,
+		eventDispatchMaxQueuedBatches:   100,
+		exposureDedupMaxSize:            10000,
 	}, *NewConfigBuilder().Region(RegionDefault).Build())
 }
+
+func TestConfigBuilder(t *testing.T) {
+
+	t.Run("custom values", func(t *testing.T) {
+		config := NewConfigBuilder().
+			PollingInterval(30*time.Second).
+			EventFlushInterval(5*time.Second).
+			EventQueueCapacity(50000).
+			EventDispatchSize(500).
+			HttpTimeout(3*time.Second).
+			EventDispatchMaxRetries(5).
+			EventDispatchBackoff(500*time.Millisecond, time.Minute).
+			EventDispatchMaxInFlightRetries(20).
+			Build()
+
+		assert.Equal(t, 30*time.Second, config.pollingInterval)
+		assert.Equal(t, 5*time.Second, config.eventFlushInterval)
+		assert.Equal(t, 50000, config.eventQueueCapacity)
+		assert.Equal(t, 500, config.eventDispatchSize)
+		assert.Equal(t, 3*time.Second, config.httpTimeout)
+		assert.Equal(t, 5, config.eventDispatchMaxRetries)
+		assert.Equal(t, 500*time.Millisecond, config.eventDispatchInitialBackoff)
+		assert.Equal(t, time.Minute, config.eventDispatchMaxBackoff)
+		assert.Equal(t, 20, config.eventDispatchMaxInFlightRetries)
+	})
+
+	t.Run("when value out of range then use default value", func(t *testing.T) {
+		config := NewConfigBuilder().
+			PollingInterval(100*time.Millisecond).
+			EventFlushInterval(time.Hour).
+			EventQueueCapacity(0).
+			EventDispatchSize(-1).
+			HttpTimeout(0).
+			EventDispatchMaxRetries(100).
+			EventDispatchBackoff(time.Millisecond, time.Hour).
+			EventDispatchMaxInFlightRetries(0).
+			Build()
+
+		assert.Equal(t, DefaultPollingInterval, config.pollingInterval)
+		assert.Equal(t, DefaultEventFlushInterval, config.eventFlushInterval)
+		assert.Equal(t, DefaultEventQueueCapacity, config.eventQueueCapacity)
+		assert.Equal(t, DefaultEventDispatchSize, config.eventDispatchSize)
+		assert.Equal(t, DefaultHttpTimeout, config.httpTimeout)
+		assert.Equal(t, DefaultEventDispatchMaxRetries, config.eventDispatchMaxRetries)
+		assert.Equal(t, DefaultEventDispatchInitialBackoff, config.eventDispatchInitialBackoff)
+		assert.Equal(t, DefaultEventDispatchMaxBackoff, config.eventDispatchMaxBackoff)
+		assert.Equal(t, DefaultEventDispatchMaxInFlightRetries, config.eventDispatchMaxInFlightRetries)
+	})
+
+	t.Run("when max backoff is less than initial backoff then use initial backoff", func(t *testing.T) {
+		config := NewConfigBuilder().
+			EventDispatchBackoff(2*time.Minute, time.Second).
+			Build()
+
+		assert.Equal(t, 2*time.Minute, config.eventDispatchInitialBackoff)
+		assert.Equal(t, 2*time.Minute, config.eventDispatchMaxBackoff)
+	})
+
+	t.Run("event store", func(t *testing.T) {
+		config := NewConfigBuilder().
+			EventStoreDirectory("/tmp/hackle").
+			EventStoreMaxBytes(1).
+			Build()
+
+		assert.Equal(t, "/tmp/hackle", config.eventStoreDirectory)
+		assert.Equal(t, DefaultEventStoreMaxBytes, config.eventStoreMaxBytes)
+		assert.IsType(t, event.NewFileStore("", 0), config.newEventStore())
+		assert.IsType(t, event.NewNoopStore(), NewConfigBuilder().Build().newEventStore())
+	})
+
+	t.Run("event overflow policy", func(t *testing.T) {
+		config := NewConfigBuilder().
+			EventOverflowPolicy(EventOverflowBlock, time.Second).
+			Build()
+
+		assert.Equal(t, EventOverflowBlock, config.eventOverflowPolicy)
+		assert.Equal(t, time.Second, config.eventOverflowBlockTimeout)
+	})
+
+	t.Run("event payload", func(t *testing.T) {
+		config := NewConfigBuilder().
+			EventCompression(true).
+			EventMaxPayloadBytes(64 * 1024).
+			Build()
+
+		assert.Equal(t, true, config.eventCompression)
+		assert.Equal(t, 64*1024, config.eventMaxPayloadBytes)
+		assert.Equal(t, DefaultEventMaxPayloadBytes, NewConfigBuilder().EventMaxPayloadBytes(1).Build().eventMaxPayloadBytes)
+	})
+
+	t.Run("event dispatch concurrency", func(t *testing.T) {
+		assert.Equal(t, 16, NewConfigBuilder().EventDispatchConcurrency(16).Build().eventDispatchConcurrency)
+		assert.Equal(t, DefaultEventDispatchConcurrency, NewConfigBuilder().EventDispatchConcurrency(0).Build().eventDispatchConcurrency)
+	})
+
+	t.Run("event dispatch max queued batches", func(t *testing.T) {
+		assert.Equal(t, 10, NewConfigBuilder().EventDispatchMaxQueuedBatches(10).Build().eventDispatchMaxQueuedBatches)
+		assert.Equal(t, DefaultEventDispatchMaxQueuedBatches, NewConfigBuilder().EventDispatchMaxQueuedBatches(0).Build().eventDispatchMaxQueuedBatches)
+	})
+
+	t.Run("event sinks", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewWriterEventSink(&buf)
+		config := NewConfigBuilder().
+			AddEventSink(sink).
+			AddEventSink(sink).
+			Build()
+
+		assert.Equal(t, 2, len(config.eventSinks))
+		dispatcher := config.withEventSinks(&mockEventDispatcher{inFlight: 1, queued: 2})
+		assert.Equal(t, 1, dispatcher.InFlightCount())
+		assert.Equal(t, 2, dispatcher.QueuedCount())
+		assert.IsType(t, &mockEventDispatcher{}, NewConfigBuilder().Build().withEventSinks(&mockEventDispatcher{}))
+	})
+
+	t.Run("exposure deduplication", func(t *testing.T) {
+		config := NewConfigBuilder().
+			ExposureDeduplication(time.Minute, 100).
+			Build()
+
+		assert.Equal(t, time.Minute, config.exposureDedupInterval)
+		assert.Equal(t, 100, config.exposureDedupMaxSize)
+		assert.IsType(t, event.NewDedupProcessor(nil, nil), config.withExposureDedup(event.NewNoopProcessor()))
+
+		config = NewConfigBuilder().
+			ExposureDeduplication(time.Millisecond, 0).
+			Build()
+		assert.Equal(t, time.Duration(0), config.exposureDedupInterval)
+		assert.Equal(t, DefaultExposureDedupMaxSize, config.exposureDedupMaxSize)
+		assert.Equal(t, event.NewNoopProcessor(), config.withExposureDedup(event.NewNoopProcessor()))
+	})
+
+	t.Run("event filters", func(t *testing.T) {
+		config := NewConfigBuilder().
+			SampleTrackEvent("page_view", 0.1).
+			SampleTrackEvent("invalid", 1.5).
+			DropEvents(func(event UserEvent) bool { return false }).
+			Build()
+
+		assert.Equal(t, map[string]float64{"page_view": 0.1}, config.trackSamplingRates)
+		assert.Equal(t, 1, len(config.eventDropPredicates))
+		assert.IsType(t, event.NewFilteringProcessor(nil, nil), config.withEventFilters(event.NewNoopProcessor()))
+		assert.Equal(t, event.NewNoopProcessor(), NewConfigBuilder().Build().withEventFilters(event.NewNoopProcessor()))
+	})
+
+	t.Run("track validation", func(t *testing.T) {
+		assert.Equal(t, TrackValidationOff, NewConfigBuilder().Build().trackValidationMode)
+		assert.Equal(t, TrackValidationWarn, NewConfigBuilder().TrackValidation(TrackValidationWarn).Build().trackValidationMode)
+	})
+
+	t.Run("dead letter handler", func(t *testing.T) {
+		var deadLetters [][]UserEvent
+		config := NewConfigBuilder().
+			EventDeadLetterHandler(func(userEvents []UserEvent) {
+				deadLetters = append(deadLetters, userEvents)
+			}).
+			Build()
+
+		config.eventDeadLetterHandler(nil)
+		assert.Equal(t, 1, len(deadLetters))
+	})
+}
+
+func TestConfig_newHttpClient(t *testing.T) {
+
+	t.Run("default", func(t *testing.T) {
+		client := NewConfigBuilder().HttpTimeout(3 * time.Second).Build().newHttpClient()
+		assert.Equal(t, 3*time.Second, client.Timeout)
+		assert.Nil(t, client.Transport)
+	})
+
+	t.Run("transport", func(t *testing.T) {
+		transport := &http.Transport{}
+		client := NewConfigBuilder().HttpTransport(transport).Build().newHttpClient()
+		assert.Same(t, transport, client.Transport)
+		assert.Equal(t, DefaultHttpTimeout, client.Timeout)
+	})
+
+	t.Run("http client", func(t *testing.T) {
+		httpClient := &http.Client{}
+		client := NewConfigBuilder().HttpClient(httpClient).HttpTimeout(3 * time.Second).Build().newHttpClient()
+		assert.Same(t, httpClient, client)
+	})
+}
+
+func TestConfig_newStreamingHttpClient(t *testing.T) {
+	transport := &http.Transport{}
+	config := NewConfigBuilder().WorkspaceStreaming(true).HttpTransport(transport).Build()
+
+	client := config.newStreamingHttpClient()
+
+	assert.Equal(t, true, config.workspaceStreaming)
+	assert.Equal(t, time.Duration(0), client.Timeout)
+	assert.Same(t, transport, client.Transport)
+}
+
+func TestConfig_withEventProcessing(t *testing.T) {
+	var tracks []string
+	cfg := NewConfigBuilder().
+		DropEvents(func(userEvent UserEvent) bool {
+			track, ok := userEvent.(TrackEvent)
+			return ok && track.Event.Key() == "drop"
+		}).
+		OnTrack(func(info TrackInfo) { tracks = append(tracks, info.EventKey) }).
+		Build()
+	sut := cfg.withEventProcessing(&mockEventProcessor{})
+
+	sut.Process(event.NewTrackEvent(model.EventType{Key: "drop"}, NewEvent("drop"), user.HackleUser{}, 1))
+	sut.Process(event.NewTrackEvent(model.EventType{Key: "keep"}, NewEvent("keep"), user.HackleUser{}, 1))
+	sut.Close()
+
+	assert.Equal(t, []string{"keep"}, tracks)
+}
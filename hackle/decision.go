@@ -1,12 +1,23 @@
 package hackle
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
+)
+
+// DecisionTrace is recorded only when enabled with ConfigBuilder.DecisionTrace.
+type DecisionTrace = decision.Trace
+type DecisionTraceStep = decision.TraceStep
+type DecisionTraceCondition = decision.TraceCondition
+type DecisionTraceSlot = decision.TraceSlot
 
 type ExperimentDecision interface {
 	fmt.Stringer
 	ParameterConfig
 	Variation() string
 	Reason() string
+	IsStale() bool
+	Trace() *DecisionTrace
 }
 
 type FeatureFlagDecision interface {
@@ -14,10 +25,13 @@ type FeatureFlagDecision interface {
 	ParameterConfig
 	IsOn() bool
 	Reason() string
+	IsStale() bool
+	Trace() *DecisionTrace
 }
 
 type RemoteConfigDecision interface {
 	fmt.Stringer
 	Value() interface{}
 	Reason() string
+	IsStale() bool
 }
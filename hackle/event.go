@@ -1,6 +1,56 @@
 package hackle
 
-import "github.com/hackle-io/hackle-go-sdk/hackle/internal/properties"
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/properties"
+	"io"
+)
+
+type UserEvent = event.UserEvent
+type ExposureEvent = event.ExposureEvent
+type TrackEvent = event.TrackEvent
+type RemoteConfigEvent = event.RemoteConfigEvent
+
+type EventsAbandonedError = event.AbandonedError
+
+// Problem describes why a tracked event is invalid.
+type Problem = core.Problem
+
+const (
+	ProblemSdkNotReady       = core.ProblemSdkNotReady
+	ProblemEmptyEventKey     = core.ProblemEmptyEventKey
+	ProblemUndefinedEventKey = core.ProblemUndefinedEventKey
+	ProblemInvalidEventValue = core.ProblemInvalidEventValue
+)
+
+// EventSink receives every event batch dispatched by the client.
+// Send is called sequentially for a sink. Returned errors and panics are logged and do not affect other sinks or delivery to Hackle.
+type EventSink = event.Sink
+
+// NewWriterEventSink writes each event as a line of JSON to the writer.
+func NewWriterEventSink(writer io.Writer) EventSink {
+	return event.NewWriterSink(writer)
+}
+
+// NewFileEventSink appends each event as a line of JSON to the file, creating it if necessary.
+func NewFileEventSink(filename string) (EventSink, error) {
+	return event.NewFileSink(filename)
+}
+
+type EventWithUser struct {
+	Event Event
+	User  User
+}
+
+// TrackResult reports how many events of a bulk track call were queued for dispatch.
+// Events are dropped when the user cannot be resolved, the event is rejected or filtered, or the event queue is full.
+// The remaining events of a call are queued all together or dropped all together when the queue has no space for them.
+// With EventOverflowDropOldest, events of earlier calls evicted to make space are counted in DroppedEventCount.
+type TrackResult struct {
+	Accepted int
+	Dropped  int
+}
 
 type Event struct {
 	key        string
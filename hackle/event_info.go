@@ -0,0 +1,103 @@
+package hackle
+
+import (
+	"context"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+)
+
+type ExposureInfo struct {
+	InsertID        string
+	Timestamp       int64
+	ExperimentKey   int64
+	FeatureFlag     bool
+	VariationID     *int64
+	VariationKey    string
+	Reason          string
+	UserIdentifiers map[string]string
+	UserProperties  map[string]interface{}
+	Properties      map[string]interface{}
+	Context         context.Context
+}
+
+type TrackInfo struct {
+	InsertID        string
+	Timestamp       int64
+	EventKey        string
+	Value           float64
+	UserIdentifiers map[string]string
+	UserProperties  map[string]interface{}
+	Properties      map[string]interface{}
+	Context         context.Context
+}
+
+type RemoteConfigInfo struct {
+	InsertID        string
+	Timestamp       int64
+	ParameterKey    string
+	ValueID         *int64
+	Reason          string
+	UserIdentifiers map[string]string
+	UserProperties  map[string]interface{}
+	Properties      map[string]interface{}
+	Context         context.Context
+}
+
+func newExposureInfo(e event.ExposureEvent) ExposureInfo {
+	return ExposureInfo{
+		InsertID:        e.InsertID(),
+		Timestamp:       e.Timestamp(),
+		ExperimentKey:   e.Experiment.Key,
+		FeatureFlag:     e.Experiment.Type == model.ExperimentTypeFeatureFlag,
+		VariationID:     e.VariationID,
+		VariationKey:    e.VariationKey,
+		Reason:          e.DecisionReason,
+		UserIdentifiers: copyIdentifiers(e.User().Identifiers),
+		UserProperties:  copyProperties(e.User().Properties),
+		Properties:      copyProperties(e.Properties),
+		Context:         event.ContextOf(e),
+	}
+}
+
+func newTrackInfo(e event.TrackEvent) TrackInfo {
+	return TrackInfo{
+		InsertID:        e.InsertID(),
+		Timestamp:       e.Timestamp(),
+		EventKey:        e.Event.Key(),
+		Value:           e.Event.Value(),
+		UserIdentifiers: copyIdentifiers(e.User().Identifiers),
+		UserProperties:  copyProperties(e.User().Properties),
+		Properties:      copyProperties(e.Event.Properties()),
+		Context:         event.ContextOf(e),
+	}
+}
+
+func newRemoteConfigInfo(e event.RemoteConfigEvent) RemoteConfigInfo {
+	return RemoteConfigInfo{
+		InsertID:        e.InsertID(),
+		Timestamp:       e.Timestamp(),
+		ParameterKey:    e.Parameter.Key,
+		ValueID:         e.ValueID,
+		Reason:          e.DecisionReason,
+		UserIdentifiers: copyIdentifiers(e.User().Identifiers),
+		UserProperties:  copyProperties(e.User().Properties),
+		Properties:      copyProperties(e.Properties),
+		Context:         event.ContextOf(e),
+	}
+}
+
+func copyIdentifiers(identifiers map[string]string) map[string]string {
+	copied := make(map[string]string, len(identifiers))
+	for key, value := range identifiers {
+		copied[key] = value
+	}
+	return copied
+}
+
+func copyProperties(properties map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,78 @@
+package backoff
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type Backoff interface {
+	Next() time.Duration
+	Reset()
+}
+
+func NewExponential(initial time.Duration, max time.Duration, multiplier float64) Backoff {
+	return &exponential{
+		initial:    initial,
+		max:        max,
+		multiplier: multiplier,
+		current:    initial,
+	}
+}
+
+type exponential struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+	current    time.Duration
+	mu         sync.Mutex
+}
+
+func (b *exponential) Next() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delay := b.current
+	next := time.Duration(float64(b.current) * b.multiplier)
+	if next > b.max || next <= 0 {
+		next = b.max
+	}
+	b.current = next
+	return delay
+}
+
+func (b *exponential) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = b.initial
+}
+
+func WithJitter(backoff Backoff, fraction float64) Backoff {
+	return &jittered{
+		delegate: backoff,
+		fraction: fraction,
+	}
+}
+
+type jittered struct {
+	delegate Backoff
+	fraction float64
+}
+
+func (b *jittered) Next() time.Duration {
+	return Jitter(b.delegate.Next(), b.fraction)
+}
+
+func (b *jittered) Reset() {
+	b.delegate.Reset()
+}
+
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+var randomMu = &sync.Mutex{}
+
+// Jitter randomizes the duration within [d - d*fraction, d + d*fraction).
+func Jitter(d time.Duration, fraction float64) time.Duration {
+	randomMu.Lock()
+	r := random.Float64()
+	randomMu.Unlock()
+	return time.Duration(float64(d) * (1 + fraction*(2*r-1)))
+}
@@ -0,0 +1,49 @@
+package backoff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+
+	t.Run("next", func(t *testing.T) {
+		sut := NewExponential(time.Second, 10*time.Second, 2)
+		assert.Equal(t, 1*time.Second, sut.Next())
+		assert.Equal(t, 2*time.Second, sut.Next())
+		assert.Equal(t, 4*time.Second, sut.Next())
+		assert.Equal(t, 8*time.Second, sut.Next())
+		assert.Equal(t, 10*time.Second, sut.Next())
+		assert.Equal(t, 10*time.Second, sut.Next())
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		sut := NewExponential(time.Second, 10*time.Second, 2)
+		sut.Next()
+		sut.Next()
+		sut.Reset()
+		assert.Equal(t, 1*time.Second, sut.Next())
+	})
+}
+
+func TestWithJitter(t *testing.T) {
+	sut := WithJitter(NewExponential(time.Second, 10*time.Second, 2), 0.5)
+
+	first := sut.Next()
+	assert.True(t, first >= 500*time.Millisecond && first < 1500*time.Millisecond)
+	second := sut.Next()
+	assert.True(t, second >= 1*time.Second && second < 3*time.Second)
+
+	sut.Reset()
+	reset := sut.Next()
+	assert.True(t, reset >= 500*time.Millisecond && reset < 1500*time.Millisecond)
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		actual := Jitter(10*time.Second, 0.2)
+		assert.True(t, actual >= 8*time.Second && actual < 12*time.Second)
+	}
+	assert.Equal(t, 10*time.Second, Jitter(10*time.Second, 0))
+}
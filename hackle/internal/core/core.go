@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/config"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
@@ -9,42 +10,67 @@ import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/evaluator/experiment"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/evaluator/remoteconfig"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
+	"sync/atomic"
 )
 
 type Core interface {
-	Experiment(experimentKey int64, user user.HackleUser, defaultVariation string) (decision.ExperimentDecision, error)
-	FeatureFlag(featureKey int64, user user.HackleUser) (decision.FeatureFlagDecision, error)
-	RemoteConfig(parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}) (decision.RemoteConfigDecision, error)
-	Track(e event.HackleEvent, user user.HackleUser)
+	Experiment(ctx context.Context, experimentKey int64, user user.HackleUser, defaultVariation string, exposure bool) (decision.ExperimentDecision, error)
+	FeatureFlag(ctx context.Context, featureKey int64, user user.HackleUser, exposure bool) (decision.FeatureFlagDecision, error)
+	RemoteConfig(ctx context.Context, parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}, exposure bool) (decision.RemoteConfigDecision, error)
+	AllExperiments(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.ExperimentDecision
+	AllFeatureFlags(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.FeatureFlagDecision
+	AllRemoteConfigs(ctx context.Context, user user.HackleUser, exposure bool) map[string]decision.RemoteConfigDecision
+	Track(ctx context.Context, e event.HackleEvent, user user.HackleUser)
+	TrackMany(ctx context.Context, requests []TrackRequest) (accepted int)
+	ValidateEvent(e event.HackleEvent) []Problem
+	InvalidEventCount() int64
 	Close()
+	CloseWithContext(ctx context.Context) error
 }
 
-func New(workspaceFetcher workspace.Fetcher, eventProcessor event.Processor) Core {
-	experimentEvaluator, remoteConfigEvaluator := evaluation.NewEvaluators()
+type TrackRequest struct {
+	Event event.HackleEvent
+	User  user.HackleUser
+}
+
+func New(
+	workspaceFetcher workspace.Fetcher,
+	eventProcessor event.Processor,
+	trackValidationMode TrackValidationMode,
+	decisionTrace bool,
+	localOverrides experiment.LocalOverrides,
+) Core {
+	experimentEvaluator, remoteConfigEvaluator := evaluation.NewEvaluators(localOverrides)
 	return &core{
 		experimentEvaluator:   experimentEvaluator,
 		remoteConfigEvaluator: remoteConfigEvaluator,
 		workspaceFetcher:      workspaceFetcher,
 		eventFactory:          event.NewFactory(clock.System),
 		eventProcessor:        eventProcessor,
+		trackValidationMode:   trackValidationMode,
+		decisionTrace:         decisionTrace,
 		clock:                 clock.System,
 	}
 }
 
 type core struct {
+	invalidEventCount     int64
 	experimentEvaluator   experiment.Evaluator
 	remoteConfigEvaluator remoteconfig.Evaluator
 	workspaceFetcher      workspace.Fetcher
 	eventFactory          event.Factory
 	eventProcessor        event.Processor
+	trackValidationMode   TrackValidationMode
+	decisionTrace         bool
 	clock                 clock.Clock
 }
 
-func (c *core) Experiment(experimentKey int64, user user.HackleUser, defaultVariation string) (decision.ExperimentDecision, error) {
+func (c *core) Experiment(ctx context.Context, experimentKey int64, user user.HackleUser, defaultVariation string, exposure bool) (decision.ExperimentDecision, error) {
 	ws, ok := c.workspaceFetcher.Fetch()
 	if !ok {
 		return decision.NewExperimentDecision(defaultVariation, decision.ReasonSdkNotReady, config.Empty()), nil
@@ -55,25 +81,37 @@ func (c *core) Experiment(experimentKey int64, user user.HackleUser, defaultVari
 		return decision.NewExperimentDecision(defaultVariation, decision.ReasonExperimentNotFound, config.Empty()), nil
 	}
 
-	req := experiment.NewRequest(ws, user, exp, defaultVariation)
-	eval, err := c.experimentEvaluator.EvaluateExperiment(req, evaluator.NewContext())
+	evaluatorContext := c.newEvaluatorContext()
+	eval, err := c.evaluateExperiment(ctx, ws, exp, user, defaultVariation, evaluatorContext, exposure)
 	if err != nil {
 		return decision.ExperimentDecision{}, err
 	}
+	d := decision.NewExperimentDecision(eval.VariationKey, eval.Reason(), eval.Config())
+	return d.WithStale(workspace.IsSnapshot(ws)).WithTrace(evaluatorContext.Trace()), nil
+}
 
-	events, err := c.eventFactory.Create(req, eval)
-	if err != nil {
-		return decision.ExperimentDecision{}, err
+func (c *core) AllExperiments(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.ExperimentDecision {
+	decisions := make(map[int64]decision.ExperimentDecision)
+	ws, ok := c.workspaceFetcher.Fetch()
+	if !ok {
+		return decisions
 	}
 
-	for _, it := range events {
-		c.eventProcessor.Process(it)
+	evaluatorContext := evaluator.NewContext()
+	for _, exp := range ws.GetExperiments() {
+		eval, err := c.evaluateExperiment(ctx, ws, exp, user, "A", evaluatorContext, exposure)
+		if err != nil {
+			logger.Error("Unexpected error while deciding variation for experiment[%d]. Returning control variation[A]: %v", exp.Key, err)
+			decisions[exp.Key] = decision.NewExperimentDecision("A", decision.ReasonException, config.Empty())
+			continue
+		}
+		d := decision.NewExperimentDecision(eval.VariationKey, eval.Reason(), eval.Config())
+		decisions[exp.Key] = d.WithStale(workspace.IsSnapshot(ws))
 	}
-
-	return decision.NewExperimentDecision(eval.VariationKey, eval.Reason(), eval.Config()), nil
+	return decisions
 }
 
-func (c *core) FeatureFlag(featureKey int64, user user.HackleUser) (decision.FeatureFlagDecision, error) {
+func (c *core) FeatureFlag(ctx context.Context, featureKey int64, user user.HackleUser, exposure bool) (decision.FeatureFlagDecision, error) {
 	ws, ok := c.workspaceFetcher.Fetch()
 	if !ok {
 		return decision.NewFeatureFlagDecision(false, decision.ReasonSdkNotReady, config.Empty()), nil
@@ -84,26 +122,70 @@ func (c *core) FeatureFlag(featureKey int64, user user.HackleUser) (decision.Fea
 		return decision.NewFeatureFlagDecision(false, decision.ReasonFeatureFlagNotFound, config.Empty()), nil
 	}
 
-	req := experiment.NewRequest(ws, user, flag, "A")
-	eval, err := c.experimentEvaluator.EvaluateExperiment(req, evaluator.NewContext())
+	evaluatorContext := c.newEvaluatorContext()
+	eval, err := c.evaluateExperiment(ctx, ws, flag, user, "A", evaluatorContext, exposure)
 	if err != nil {
 		return decision.FeatureFlagDecision{}, err
 	}
 
-	events, err := c.eventFactory.Create(req, eval)
-	if err != nil {
-		return decision.FeatureFlagDecision{}, err
+	isOn := eval.VariationKey != "A"
+	d := decision.NewFeatureFlagDecision(isOn, eval.Reason(), eval.Config())
+	return d.WithStale(workspace.IsSnapshot(ws)).WithTrace(evaluatorContext.Trace()), nil
+}
+
+func (c *core) AllFeatureFlags(ctx context.Context, user user.HackleUser, exposure bool) map[int64]decision.FeatureFlagDecision {
+	decisions := make(map[int64]decision.FeatureFlagDecision)
+	ws, ok := c.workspaceFetcher.Fetch()
+	if !ok {
+		return decisions
 	}
 
-	for _, it := range events {
-		c.eventProcessor.Process(it)
+	evaluatorContext := evaluator.NewContext()
+	for _, flag := range ws.GetFeatureFlags() {
+		eval, err := c.evaluateExperiment(ctx, ws, flag, user, "A", evaluatorContext, exposure)
+		if err != nil {
+			logger.Error("Unexpected error while deciding feature flag[%d]. Returning control flag[false]: %v", flag.Key, err)
+			decisions[flag.Key] = decision.NewFeatureFlagDecision(false, decision.ReasonException, config.Empty())
+			continue
+		}
+		isOn := eval.VariationKey != "A"
+		d := decision.NewFeatureFlagDecision(isOn, eval.Reason(), eval.Config())
+		decisions[flag.Key] = d.WithStale(workspace.IsSnapshot(ws))
 	}
+	return decisions
+}
 
-	isOn := eval.VariationKey != "A"
-	return decision.NewFeatureFlagDecision(isOn, eval.Reason(), eval.Config()), nil
+func (c *core) newEvaluatorContext() evaluator.Context {
+	if c.decisionTrace {
+		return evaluator.NewTracingContext()
+	}
+	return evaluator.NewContext()
 }
 
-func (c *core) RemoteConfig(parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}) (decision.RemoteConfigDecision, error) {
+func (c *core) evaluateExperiment(
+	ctx context.Context,
+	ws workspace.Workspace,
+	exp model.Experiment,
+	user user.HackleUser,
+	defaultVariation string,
+	evaluatorContext evaluator.Context,
+	exposure bool,
+) (experiment.Evaluation, error) {
+	req := experiment.NewRequest(ws, user, exp, defaultVariation)
+	evaluated := len(evaluatorContext.Evaluations())
+	eval, err := c.experimentEvaluator.EvaluateExperiment(req, evaluatorContext)
+	if err != nil {
+		return experiment.Evaluation{}, err
+	}
+	if exposure {
+		if err := c.emit(ctx, req, eval, evaluated); err != nil {
+			return experiment.Evaluation{}, err
+		}
+	}
+	return eval, nil
+}
+
+func (c *core) RemoteConfig(ctx context.Context, parameterKey string, user user.HackleUser, requiredType types.ValueType, defaultValue interface{}, exposure bool) (decision.RemoteConfigDecision, error) {
 	ws, ok := c.workspaceFetcher.Fetch()
 	if !ok {
 		return decision.NewRemoteConfigDecision(defaultValue, decision.ReasonSdkNotReady), nil
@@ -114,32 +196,114 @@ func (c *core) RemoteConfig(parameterKey string, user user.HackleUser, requiredT
 		return decision.NewRemoteConfigDecision(defaultValue, decision.ReasonRemoteConfigParameterNotFound), nil
 	}
 
-	req := remoteconfig.NewRequest(ws, user, param, requiredType, defaultValue)
-	eval, err := c.remoteConfigEvaluator.EvaluateRemoteConfig(req, evaluator.NewContext())
+	eval, err := c.evaluateRemoteConfig(ctx, ws, param, user, requiredType, defaultValue, evaluator.NewContext(), exposure)
 	if err != nil {
 		return decision.RemoteConfigDecision{}, err
 	}
+	d := decision.NewRemoteConfigDecision(eval.Value, eval.Reason())
+	return d.WithStale(workspace.IsSnapshot(ws)), nil
+}
+
+func (c *core) AllRemoteConfigs(ctx context.Context, user user.HackleUser, exposure bool) map[string]decision.RemoteConfigDecision {
+	decisions := make(map[string]decision.RemoteConfigDecision)
+	ws, ok := c.workspaceFetcher.Fetch()
+	if !ok {
+		return decisions
+	}
 
+	evaluatorContext := evaluator.NewContext()
+	for _, param := range ws.GetRemoteConfigParameters() {
+		defaultValue := param.DefaultValue.RawValue
+		eval, err := c.evaluateRemoteConfig(ctx, ws, param, user, param.Type, defaultValue, evaluatorContext, exposure)
+		if err != nil {
+			logger.Error("Unexpected exception while deciding remote config parameter[%s]. Returning default value[%v]: %v", param.Key, defaultValue, err)
+			decisions[param.Key] = decision.NewRemoteConfigDecision(defaultValue, decision.ReasonException)
+			continue
+		}
+		d := decision.NewRemoteConfigDecision(eval.Value, eval.Reason())
+		decisions[param.Key] = d.WithStale(workspace.IsSnapshot(ws))
+	}
+	return decisions
+}
+
+func (c *core) evaluateRemoteConfig(
+	ctx context.Context,
+	ws workspace.Workspace,
+	param model.RemoteConfigParameter,
+	user user.HackleUser,
+	requiredType types.ValueType,
+	defaultValue interface{},
+	evaluatorContext evaluator.Context,
+	exposure bool,
+) (remoteconfig.Evaluation, error) {
+	req := remoteconfig.NewRequest(ws, user, param, requiredType, defaultValue)
+	evaluated := len(evaluatorContext.Evaluations())
+	eval, err := c.remoteConfigEvaluator.EvaluateRemoteConfig(req, evaluatorContext)
+	if err != nil {
+		return remoteconfig.Evaluation{}, err
+	}
+	if exposure {
+		if err := c.emit(ctx, req, eval, evaluated); err != nil {
+			return remoteconfig.Evaluation{}, err
+		}
+	}
+	return eval, nil
+}
+
+// emit skips the first evaluated target evaluations, which were already
+// emitted by an earlier evaluation sharing the same evaluator.Context.
+func (c *core) emit(ctx context.Context, req evaluator.Request, eval evaluator.Evaluation, evaluated int) error {
 	events, err := c.eventFactory.Create(req, eval)
 	if err != nil {
-		return decision.RemoteConfigDecision{}, err
+		return err
+	}
+	if evaluated > 0 && len(events) > evaluated {
+		events = append(events[:1], events[1+evaluated:]...)
 	}
 	for _, it := range events {
-		c.eventProcessor.Process(it)
+		c.eventProcessor.Process(event.WithContext(ctx, it))
 	}
-
-	return decision.NewRemoteConfigDecision(eval.Value, eval.Reason()), nil
+	return nil
 }
 
-func (c *core) Track(e event.HackleEvent, user user.HackleUser) {
-	eventType := c.eventType(e)
+func (c *core) Track(ctx context.Context, e event.HackleEvent, user user.HackleUser) {
+	ws := c.trackWorkspace()
+	if !c.isValid(e, ws) {
+		return
+	}
+	eventType := c.eventType(ws, e)
 	trackEvent := event.NewTrackEvent(eventType, e, user, c.clock.CurrentMillis())
-	c.eventProcessor.Process(trackEvent)
+	c.eventProcessor.Process(event.WithContext(ctx, trackEvent))
 }
 
-func (c *core) eventType(event event.HackleEvent) model.EventType {
+func (c *core) TrackMany(ctx context.Context, requests []TrackRequest) int {
+	ws := c.trackWorkspace()
+	timestamp := c.clock.CurrentMillis()
+	trackEvents := make([]event.UserEvent, 0, len(requests))
+	for _, request := range requests {
+		if !c.isValid(request.Event, ws) {
+			continue
+		}
+		eventType := c.eventType(ws, request.Event)
+		trackEvent := event.NewTrackEvent(eventType, request.Event, request.User, timestamp)
+		trackEvents = append(trackEvents, event.WithContext(ctx, trackEvent))
+	}
+	if len(trackEvents) == 0 {
+		return 0
+	}
+	return c.eventProcessor.ProcessAll(trackEvents)
+}
+
+func (c *core) trackWorkspace() workspace.Workspace {
 	ws, ok := c.workspaceFetcher.Fetch()
 	if !ok {
+		return nil
+	}
+	return ws
+}
+
+func (c *core) eventType(ws workspace.Workspace, event event.HackleEvent) model.EventType {
+	if ws == nil {
 		return model.NewUndefinedEvent(event.Key())
 	}
 	if eventType, ok := ws.GetEventType(event.Key()); ok {
@@ -149,7 +313,45 @@ func (c *core) eventType(event event.HackleEvent) model.EventType {
 	}
 }
 
+func (c *core) isValid(e event.HackleEvent, ws workspace.Workspace) bool {
+	if c.trackValidationMode == TrackValidationOff {
+		return true
+	}
+	problems := validateEvent(e, ws)
+	if len(problems) == 0 {
+		return true
+	}
+	atomic.AddInt64(&c.invalidEventCount, 1)
+	switch c.trackValidationMode {
+	case TrackValidationWarn:
+		logger.Warn("Invalid event [%s]: %v", e.Key(), problems)
+	case TrackValidationReject:
+		logger.Warn("Invalid event [%s] rejected: %v", e.Key(), problems)
+		return false
+	}
+	return true
+}
+
+func (c *core) ValidateEvent(e event.HackleEvent) []Problem {
+	ws, ok := c.workspaceFetcher.Fetch()
+	if !ok {
+		problems := []Problem{{Code: ProblemSdkNotReady, Message: "workspace is not loaded yet, event key cannot be validated"}}
+		return append(problems, validateEvent(e, nil)...)
+	}
+	return validateEvent(e, ws)
+}
+
+func (c *core) InvalidEventCount() int64 {
+	return atomic.LoadInt64(&c.invalidEventCount)
+}
+
 func (c *core) Close() {
 	c.eventProcessor.Close()
 	c.workspaceFetcher.Close()
 }
+
+func (c *core) CloseWithContext(ctx context.Context) error {
+	err := c.eventProcessor.CloseWithContext(ctx)
+	c.workspaceFetcher.Close()
+	return err
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
@@ -16,6 +17,7 @@ import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"math"
 	"strconv"
 	"testing"
 )
@@ -58,7 +60,7 @@ func TestCore_Experiment(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(nil, false)
 
 		// when
-		actual, err := sut.Experiment(42, user.HackleUser{}, "A")
+		actual, err := sut.Experiment(context.Background(), 42, user.HackleUser{}, "A", true)
 
 		// then
 		assert.Nil(t, err)
@@ -73,7 +75,7 @@ func TestCore_Experiment(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), false)
 
 		// when
-		actual, err := sut.Experiment(42, user.HackleUser{}, "A")
+		actual, err := sut.Experiment(context.Background(), 42, user.HackleUser{}, "A", true)
 
 		// then
 		assert.Nil(t, err)
@@ -91,7 +93,7 @@ func TestCore_Experiment(t *testing.T) {
 		f.experimentEvaluator.On("EvaluateExperiment", mock.Anything, mock.Anything).Return(experiment.Evaluation{}, errors.New("fail"))
 
 		// when
-		_, actual := sut.Experiment(42, user.HackleUser{}, "A")
+		_, actual := sut.Experiment(context.Background(), 42, user.HackleUser{}, "A", true)
 
 		// then
 		assert.Equal(t, errors.New("fail"), actual)
@@ -119,7 +121,7 @@ func TestCore_Experiment(t *testing.T) {
 		f.eventFactory.On("Create", mock.Anything, mock.Anything).Return([]event.UserEvent{}, errors.New("fail"))
 
 		// when
-		_, err := sut.Experiment(42, user.HackleUser{}, "A")
+		_, err := sut.Experiment(context.Background(), 42, user.HackleUser{}, "A", true)
 
 		// then
 		assert.Equal(t, errors.New("fail"), err)
@@ -146,7 +148,7 @@ func TestCore_Experiment(t *testing.T) {
 		f.eventProcessor.On("Process", mock.Anything).Return()
 
 		// when
-		actual, _ := sut.Experiment(42, user.HackleUser{}, "A")
+		actual, _ := sut.Experiment(context.Background(), 42, user.HackleUser{}, "A", true)
 
 		// then
 		assert.Equal(t, "B", actual.Variation())
@@ -163,7 +165,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(nil, false)
 
 		// when
-		actual, err := sut.FeatureFlag(42, user.HackleUser{})
+		actual, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Nil(t, err)
@@ -178,7 +180,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), false)
 
 		// when
-		actual, err := sut.FeatureFlag(42, user.HackleUser{})
+		actual, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Nil(t, err)
@@ -196,7 +198,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.experimentEvaluator.On("EvaluateExperiment", mock.Anything, mock.Anything).Return(experiment.Evaluation{}, errors.New("fail"))
 
 		// when
-		_, err := sut.FeatureFlag(42, user.HackleUser{})
+		_, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Equal(t, errors.New("fail"), err)
@@ -215,7 +217,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.eventFactory.MockCreateError(errors.New("failed to create events"))
 
 		// when
-		_, err := sut.FeatureFlag(42, user.HackleUser{})
+		_, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Equal(t, errors.New("failed to create events"), err)
@@ -233,7 +235,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.eventFactory.MockCreateReturn([]event.UserEvent{event.ExposureEvent{}, event.ExposureEvent{}})
 
 		// when
-		actual, err := sut.FeatureFlag(42, user.HackleUser{})
+		actual, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Nil(t, err)
@@ -254,7 +256,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.eventFactory.MockCreateReturn([]event.UserEvent{event.ExposureEvent{}, event.ExposureEvent{}})
 
 		// when
-		actual, err := sut.FeatureFlag(42, user.HackleUser{})
+		actual, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Nil(t, err)
@@ -274,7 +276,7 @@ func TestCore_FeatureFlag(t *testing.T) {
 		f.eventFactory.MockCreateReturn([]event.UserEvent{event.ExposureEvent{}, event.ExposureEvent{}})
 
 		// when
-		_, err := sut.FeatureFlag(42, user.HackleUser{})
+		_, err := sut.FeatureFlag(context.Background(), 42, user.HackleUser{}, true)
 
 		// then
 		assert.Nil(t, err)
@@ -290,7 +292,7 @@ func TestCore_RemoteConfig(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(nil, false)
 
 		// when
-		actual, err := sut.RemoteConfig("42", user.HackleUser{}, types.String, "default")
+		actual, err := sut.RemoteConfig(context.Background(), "42", user.HackleUser{}, types.String, "default", true)
 
 		// then
 		assert.Nil(t, err)
@@ -304,7 +306,7 @@ func TestCore_RemoteConfig(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
 
 		// when
-		actual, err := sut.RemoteConfig("42", user.HackleUser{}, types.String, "default")
+		actual, err := sut.RemoteConfig(context.Background(), "42", user.HackleUser{}, types.String, "default", true)
 
 		// then
 		assert.Nil(t, err)
@@ -323,7 +325,7 @@ func TestCore_RemoteConfig(t *testing.T) {
 		f.remoteConfigEvaluator.On("EvaluateRemoteConfig", mock.Anything, mock.Anything).Return(remoteconfig.Evaluation{}, errors.New("failed to evaluate"))
 
 		// when
-		_, err := sut.RemoteConfig("42", user.HackleUser{}, types.String, "default")
+		_, err := sut.RemoteConfig(context.Background(), "42", user.HackleUser{}, types.String, "default", true)
 
 		// then
 		assert.Equal(t, errors.New("failed to evaluate"), err)
@@ -341,7 +343,7 @@ func TestCore_RemoteConfig(t *testing.T) {
 		f.eventFactory.MockCreateError(errors.New("failed to create events"))
 
 		// when
-		_, err := sut.RemoteConfig("42", user.HackleUser{}, types.String, "default")
+		_, err := sut.RemoteConfig(context.Background(), "42", user.HackleUser{}, types.String, "default", true)
 
 		// then
 		assert.Equal(t, errors.New("failed to create events"), err)
@@ -367,7 +369,7 @@ func TestCore_RemoteConfig(t *testing.T) {
 		f.eventFactory.MockCreateReturn([]event.UserEvent{event.RemoteConfigEvent{}, event.ExposureEvent{}})
 
 		// when
-		actual, err := sut.RemoteConfig("42", user.HackleUser{}, types.String, "default")
+		actual, err := sut.RemoteConfig(context.Background(), "42", user.HackleUser{}, types.String, "default", true)
 
 		// then
 		assert.Nil(t, err)
@@ -385,7 +387,7 @@ func TestCore_Track(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(nil, false)
 
 		// when
-		sut.Track(mocks.CreateEvent("42"), user.HackleUser{})
+		sut.Track(context.Background(), mocks.CreateEvent("42"), user.HackleUser{})
 
 		// then
 		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
@@ -399,7 +401,7 @@ func TestCore_Track(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
 
 		// when
-		sut.Track(mocks.CreateEvent("42"), user.HackleUser{})
+		sut.Track(context.Background(), mocks.CreateEvent("42"), user.HackleUser{})
 
 		// then
 		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
@@ -416,7 +418,7 @@ func TestCore_Track(t *testing.T) {
 		f.workspaceFetcher.On("Fetch").Return(ws, true)
 
 		// when
-		sut.Track(mocks.CreateEvent("42"), user.HackleUser{})
+		sut.Track(context.Background(), mocks.CreateEvent("42"), user.HackleUser{})
 
 		// then
 		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
@@ -425,6 +427,132 @@ func TestCore_Track(t *testing.T) {
 	})
 }
 
+func TestCore_TrackMany(t *testing.T) {
+
+	t.Run("process all track events at once", func(t *testing.T) {
+		sut, f := sut()
+		ws := mocks.CreateWorkspace()
+		ws.EventType(model.EventType{ID: 42, Key: "42"})
+		f.workspaceFetcher.On("Fetch").Return(ws, true)
+		f.eventProcessor.On("ProcessAll", mock.Anything).Return(1)
+
+		accepted := sut.TrackMany(context.Background(), []TrackRequest{
+			{Event: mocks.CreateEvent("42"), User: user.HackleUser{Identifiers: map[string]string{"$id": "a"}}},
+			{Event: mocks.CreateEvent("43"), User: user.HackleUser{Identifiers: map[string]string{"$id": "b"}}},
+		})
+
+		assert.Equal(t, 1, accepted)
+		f.eventProcessor.AssertNumberOfCalls(t, "ProcessAll", 1)
+		f.eventProcessor.AssertNumberOfCalls(t, "Process", 0)
+		trackEvents := f.eventProcessor.Calls[0].Arguments[0].([]event.UserEvent)
+		assert.Equal(t, 2, len(trackEvents))
+		assert.Equal(t, int64(42), trackEvents[0].(event.TrackEvent).EventType.ID)
+		assert.Equal(t, "a", trackEvents[0].User().Identifiers["$id"])
+		assert.Equal(t, int64(0), trackEvents[1].(event.TrackEvent).EventType.ID)
+		assert.Equal(t, "b", trackEvents[1].User().Identifiers["$id"])
+	})
+
+	t.Run("skip rejected events", func(t *testing.T) {
+		sut, f := sut()
+		sut.trackValidationMode = TrackValidationReject
+		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
+
+		accepted := sut.TrackMany(context.Background(), []TrackRequest{{Event: mocks.CreateEvent("undefined")}})
+
+		assert.Equal(t, 0, accepted)
+		f.eventProcessor.AssertNumberOfCalls(t, "ProcessAll", 0)
+		assert.Equal(t, int64(1), sut.InvalidEventCount())
+	})
+}
+
+func TestCore_Track_validation(t *testing.T) {
+
+	t.Run("when validation off then track undefined event without counting", func(t *testing.T) {
+		sut, f := sut()
+		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
+
+		sut.Track(context.Background(), mocks.CreateEvent("undefined"), user.HackleUser{})
+
+		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
+		assert.Equal(t, int64(0), sut.InvalidEventCount())
+	})
+
+	for _, mode := range []TrackValidationMode{TrackValidationCount, TrackValidationWarn} {
+		t.Run("when count or warn then track and count invalid event", func(t *testing.T) {
+			sut, f := sut()
+			sut.trackValidationMode = mode
+			f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
+
+			sut.Track(context.Background(), mocks.CreateEvent("undefined"), user.HackleUser{})
+
+			f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
+			assert.Equal(t, int64(1), sut.InvalidEventCount())
+		})
+	}
+
+	t.Run("when reject then do not track invalid event", func(t *testing.T) {
+		sut, f := sut()
+		sut.trackValidationMode = TrackValidationReject
+		ws := mocks.CreateWorkspace()
+		ws.EventType(model.EventType{ID: 42, Key: "42"})
+		f.workspaceFetcher.On("Fetch").Return(ws, true)
+
+		sut.Track(context.Background(), mocks.CreateEvent("undefined"), user.HackleUser{})
+		sut.Track(context.Background(), mocks.CreateEvent("42"), user.HackleUser{})
+
+		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
+		assert.Equal(t, int64(1), sut.InvalidEventCount())
+	})
+
+	t.Run("when sdk not ready then do not reject undefined event", func(t *testing.T) {
+		sut, f := sut()
+		sut.trackValidationMode = TrackValidationReject
+		f.workspaceFetcher.On("Fetch").Return(nil, false)
+
+		sut.Track(context.Background(), mocks.CreateEvent("42"), user.HackleUser{})
+
+		f.eventProcessor.AssertNumberOfCalls(t, "Process", 1)
+		assert.Equal(t, int64(0), sut.InvalidEventCount())
+	})
+}
+
+func TestCore_ValidateEvent(t *testing.T) {
+
+	t.Run("valid event", func(t *testing.T) {
+		sut, f := sut()
+		ws := mocks.CreateWorkspace()
+		ws.EventType(model.EventType{ID: 42, Key: "42"})
+		f.workspaceFetcher.On("Fetch").Return(ws, true)
+
+		assert.Equal(t, 0, len(sut.ValidateEvent(mocks.CreateEvent("42"))))
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		sut, f := sut()
+		f.workspaceFetcher.On("Fetch").Return(mocks.CreateWorkspace(), true)
+
+		problems := sut.ValidateEvent(mocks.CreateEventWithValue("", math.NaN()))
+		assert.Equal(t, 2, len(problems))
+		assert.Equal(t, ProblemEmptyEventKey, problems[0].Code)
+		assert.Equal(t, ProblemInvalidEventValue, problems[1].Code)
+
+		problems = sut.ValidateEvent(mocks.CreateEventWithValue("undefined", math.Inf(1)))
+		assert.Equal(t, 2, len(problems))
+		assert.Equal(t, ProblemUndefinedEventKey, problems[0].Code)
+		assert.Equal(t, "UNDEFINED_EVENT_KEY: event key [undefined] is not defined in the workspace", problems[0].String())
+		assert.Equal(t, ProblemInvalidEventValue, problems[1].Code)
+	})
+
+	t.Run("when sdk not ready then sdk not ready problem", func(t *testing.T) {
+		sut, f := sut()
+		f.workspaceFetcher.On("Fetch").Return(nil, false)
+
+		problems := sut.ValidateEvent(mocks.CreateEvent("42"))
+		assert.Equal(t, 1, len(problems))
+		assert.Equal(t, ProblemSdkNotReady, problems[0].Code)
+	})
+}
+
 func TestCore_Close(t *testing.T) {
 	sut, f := sut()
 	f.eventProcessor.On("Close").Return()
@@ -434,6 +562,18 @@ func TestCore_Close(t *testing.T) {
 	f.workspaceFetcher.AssertNumberOfCalls(t, "Close", 1)
 }
 
+func TestCore_CloseWithContext(t *testing.T) {
+	sut, f := sut()
+	f.eventProcessor.On("CloseWithContext", mock.Anything).Return(errors.New("abandoned"))
+	f.workspaceFetcher.On("Close").Return()
+
+	err := sut.CloseWithContext(context.Background())
+
+	assert.Equal(t, errors.New("abandoned"), err)
+	f.eventProcessor.AssertNumberOfCalls(t, "CloseWithContext", 1)
+	f.workspaceFetcher.AssertNumberOfCalls(t, "Close", 1)
+}
+
 func TestCore(t *testing.T) {
 
 	/*
@@ -451,10 +591,10 @@ func TestCore(t *testing.T) {
 	t.Run("target_experiment", func(t *testing.T) {
 		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_target_experiment.json")
 		processor := &memoryEventProcessor{}
-		core := New(fetcher, processor)
+		core := New(fetcher, processor, TrackValidationOff, false, nil)
 		hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build()
 
-		actual, err := core.RemoteConfig("rc", hackleUser, types.String, "!!")
+		actual, err := core.RemoteConfig(context.Background(), "rc", hackleUser, types.String, "!!", true)
 
 		assert.Nil(t, err)
 		assert.Equal(t, decision.NewRemoteConfigDecision("Targeting!!", decision.ReasonTargetRuleMatch), actual)
@@ -475,6 +615,82 @@ func TestCore(t *testing.T) {
 		}
 	})
 
+	t.Run("target_experiment_all", func(t *testing.T) {
+		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_target_experiment.json")
+		processor := &memoryEventProcessor{}
+		core := New(fetcher, processor, TrackValidationOff, false, nil)
+		hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build()
+
+		experiments := core.AllExperiments(context.Background(), hackleUser, false)
+		featureFlags := core.AllFeatureFlags(context.Background(), hackleUser, false)
+		remoteConfigs := core.AllRemoteConfigs(context.Background(), hackleUser, false)
+		assert.Equal(t, 0, len(processor.events))
+
+		for key, d := range experiments {
+			single, err := core.Experiment(context.Background(), key, hackleUser, "A", true)
+			assert.Nil(t, err)
+			assert.Equal(t, single, d)
+		}
+		for key, d := range featureFlags {
+			single, err := core.FeatureFlag(context.Background(), key, hackleUser, true)
+			assert.Nil(t, err)
+			assert.Equal(t, single, d)
+		}
+		assert.Equal(t, "Targeting!!", remoteConfigs["rc"].Value())
+
+		processor.events = nil
+		core.AllExperiments(context.Background(), hackleUser, true)
+		exposed := make(map[int64]int)
+		targetExposed := make(map[int64]int)
+		for _, userEvent := range processor.events {
+			exposure := userEvent.(event.ExposureEvent)
+			if _, ok := exposure.Properties["$targetingRootId"]; ok {
+				targetExposed[exposure.Experiment.Key]++
+			} else {
+				exposed[exposure.Experiment.Key]++
+			}
+		}
+		for key := range experiments {
+			assert.Equal(t, 1, exposed[key])
+		}
+		assert.NotEmpty(t, targetExposed)
+		for _, count := range targetExposed {
+			assert.Equal(t, 1, count)
+		}
+	})
+
+	t.Run("target_experiment_trace", func(t *testing.T) {
+		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_target_experiment.json")
+		hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build()
+
+		d, err := New(fetcher, &memoryEventProcessor{}, TrackValidationOff, false, nil).FeatureFlag(context.Background(), 4, hackleUser, true)
+		assert.Nil(t, err)
+		assert.Nil(t, d.Trace())
+
+		d, err = New(fetcher, &memoryEventProcessor{}, TrackValidationOff, true, nil).FeatureFlag(context.Background(), 4, hackleUser, true)
+		assert.Nil(t, err)
+
+		var decided []decision.TraceStep
+		for _, step := range d.Trace().Steps {
+			if step.Reason != "" {
+				decided = append(decided, step)
+			}
+		}
+		assert.Equal(t, 3, len(decided))
+
+		assert.Equal(t, int64(4), decided[0].ExperimentKey)
+		assert.Equal(t, decision.TraceFlowTargetRule, decided[0].Flow)
+		assert.Equal(t, d.Reason(), decided[0].Reason)
+		assert.Equal(t, 0, *decided[0].TargetRuleIndex)
+		assert.Equal(t, []decision.TraceCondition{{KeyType: "FEATURE_FLAG", Key: "5", Matched: true}}, decided[0].Conditions)
+		assert.Equal(t, 1, len(decided[0].Slots))
+
+		assert.Equal(t, int64(5), decided[1].ExperimentKey)
+		assert.Equal(t, int64(6), decided[2].ExperimentKey)
+		assert.Equal(t, decision.TraceFlowTrafficAllocate, decided[2].Flow)
+		assert.Equal(t, decision.ReasonTrafficAllocated, decided[2].Reason)
+	})
+
 	/*
 	 *     RC(1)
 	 *      ↓
@@ -488,10 +704,10 @@ func TestCore(t *testing.T) {
 	t.Run("target_experiment_circular", func(t *testing.T) {
 		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_target_experiment_circular.json")
 		processor := &memoryEventProcessor{}
-		core := New(fetcher, processor)
+		core := New(fetcher, processor, TrackValidationOff, false, nil)
 		hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "a").Build()
 
-		_, err := core.RemoteConfig("rc", hackleUser, types.String, "!!")
+		_, err := core.RemoteConfig(context.Background(), "rc", hackleUser, types.String, "!!", true)
 
 		assert.NotNil(t, err)
 		assert.Contains(t, err.Error(), "circular evaluation has occurred")
@@ -509,12 +725,12 @@ func TestCore(t *testing.T) {
 	t.Run("container", func(t *testing.T) {
 		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_container.json")
 		processor := &memoryEventProcessor{}
-		core := New(fetcher, processor)
+		core := New(fetcher, processor, TrackValidationOff, false, nil)
 
 		decisions := make([]decision.ExperimentDecision, 0)
 		for i := 0; i < 10000; i++ {
 			u := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, strconv.Itoa(i)).Build()
-			d, err := core.Experiment(2, u, "A")
+			d, err := core.Experiment(context.Background(), 2, u, "A", true)
 			assert.Nil(t, err)
 			decisions = append(decisions, d)
 		}
@@ -539,18 +755,63 @@ func TestCore(t *testing.T) {
 	t.Run("segment_match", func(t *testing.T) {
 		fetcher := workspace.NewFileFetcher("../../../testdata/workspace_segment_match.json")
 		processor := &memoryEventProcessor{}
-		core := New(fetcher, processor)
+		core := New(fetcher, processor, TrackValidationOff, false, nil)
 
-		d1, _ := core.Experiment(1, user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "matched_id").Build(), "A")
+		d1, _ := core.Experiment(context.Background(), 1, user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "matched_id").Build(), "A", true)
 		assert.Equal(t, "A", d1.Variation())
 		assert.Equal(t, "OVERRIDDEN", d1.Reason())
 
-		d2, _ := core.Experiment(1, user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "not_matched_id").Build(), "A")
+		d2, _ := core.Experiment(context.Background(), 1, user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "not_matched_id").Build(), "A", true)
 		assert.Equal(t, "A", d2.Variation())
 		assert.Equal(t, "TRAFFIC_ALLOCATED", d2.Reason())
 	})
 }
 
+func TestCore_snapshot(t *testing.T) {
+	ws, ok := workspace.NewFileSnapshot("../../../testdata/workspace_config.json").Load()
+	assert.Equal(t, true, ok)
+
+	processor := &memoryEventProcessor{}
+	core := New(workspace.NewStaticFetcher(ws), processor, TrackValidationOff, false, nil)
+	hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build()
+
+	experimentDecision, err := core.Experiment(context.Background(), 7, hackleUser, "A", true)
+	assert.Nil(t, err)
+	assert.Equal(t, true, experimentDecision.IsStale())
+	assert.Equal(t, processor.events[0].(event.ExposureEvent).DecisionReason, experimentDecision.Reason())
+
+	featureFlagDecision, err := core.FeatureFlag(context.Background(), 1, hackleUser, true)
+	assert.Nil(t, err)
+	assert.Equal(t, true, featureFlagDecision.IsStale())
+	assert.Equal(t, "FEATURE_FLAG_INACTIVE", featureFlagDecision.Reason())
+
+	remoteConfigDecision, err := core.RemoteConfig(context.Background(), "json_key_1", hackleUser, types.String, "default", false)
+	assert.Nil(t, err)
+	assert.Equal(t, true, remoteConfigDecision.IsStale())
+
+	assert.Equal(t, 2, len(processor.events))
+	assert.Equal(t, "FEATURE_FLAG_INACTIVE", processor.events[1].(event.ExposureEvent).DecisionReason)
+}
+
+func TestCore_withoutExposure(t *testing.T) {
+	processor := &memoryEventProcessor{}
+	core := New(workspace.NewFileFetcher("../../../testdata/workspace_config.json"), processor, TrackValidationOff, false, nil)
+	hackleUser := user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build()
+
+	peeked, err := core.Experiment(context.Background(), 7, hackleUser, "A", false)
+	assert.Nil(t, err)
+	_, err = core.FeatureFlag(context.Background(), 1, hackleUser, false)
+	assert.Nil(t, err)
+	_, err = core.RemoteConfig(context.Background(), "json_key_1", hackleUser, types.String, "default", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(processor.events))
+
+	exposed, err := core.Experiment(context.Background(), 7, hackleUser, "A", true)
+	assert.Nil(t, err)
+	assert.Equal(t, peeked, exposed)
+	assert.Equal(t, 1, len(processor.events))
+}
+
 type memoryEventProcessor struct {
 	events []event.UserEvent
 }
@@ -559,10 +820,27 @@ func (p *memoryEventProcessor) Process(event event.UserEvent) {
 	p.events = append(p.events, event)
 }
 
+func (p *memoryEventProcessor) ProcessAll(events []event.UserEvent) int {
+	p.events = append(p.events, events...)
+	return len(events)
+}
+
+func (p *memoryEventProcessor) DroppedCount() int64 {
+	return 0
+}
+
 func (p *memoryEventProcessor) Start() {}
 
+func (p *memoryEventProcessor) Flush(ctx context.Context) error {
+	return nil
+}
+
 func (p *memoryEventProcessor) Close() {}
 
+func (p *memoryEventProcessor) CloseWithContext(ctx context.Context) error {
+	return nil
+}
+
 type mockExperimentEvaluator struct{ mock.Mock }
 
 func (m *mockExperimentEvaluator) EvaluateExperiment(request experiment.Request, context evaluator.Context) (experiment.Evaluation, error) {
@@ -614,10 +892,26 @@ func (m *mockEventProcessor) Process(event event.UserEvent) {
 	m.Called(event)
 }
 
+func (m *mockEventProcessor) ProcessAll(events []event.UserEvent) int {
+	return m.Called(events).Int(0)
+}
+
+func (m *mockEventProcessor) DroppedCount() int64 {
+	return m.Called().Get(0).(int64)
+}
+
 func (m *mockEventProcessor) Start() {
 	m.Called()
 }
 
+func (m *mockEventProcessor) Flush(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
 func (m *mockEventProcessor) Close() {
 	m.Called()
 }
+
+func (m *mockEventProcessor) CloseWithContext(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/event"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
+	"math"
+)
+
+type TrackValidationMode int
+
+const (
+	TrackValidationOff TrackValidationMode = iota
+	TrackValidationCount
+	TrackValidationWarn
+	TrackValidationReject
+)
+
+type Problem struct {
+	Code    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Code, p.Message)
+}
+
+const (
+	ProblemSdkNotReady       = "SDK_NOT_READY"
+	ProblemEmptyEventKey     = "EMPTY_EVENT_KEY"
+	ProblemUndefinedEventKey = "UNDEFINED_EVENT_KEY"
+	ProblemInvalidEventValue = "INVALID_EVENT_VALUE"
+)
+
+func validateEvent(e event.HackleEvent, ws workspace.Workspace) []Problem {
+	var problems []Problem
+	if e.Key() == "" {
+		problems = append(problems, Problem{Code: ProblemEmptyEventKey, Message: "event key is empty"})
+	} else if ws != nil {
+		if _, ok := ws.GetEventType(e.Key()); !ok {
+			problems = append(problems, Problem{Code: ProblemUndefinedEventKey, Message: fmt.Sprintf("event key [%s] is not defined in the workspace", e.Key())})
+		}
+	}
+	if math.IsNaN(e.Value()) || math.IsInf(e.Value(), 0) {
+		problems = append(problems, Problem{Code: ProblemInvalidEventValue, Message: fmt.Sprintf("event value [%v] is not a finite number", e.Value())})
+	}
+	return problems
+}
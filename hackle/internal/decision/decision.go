@@ -9,6 +9,8 @@ type ExperimentDecision struct {
 	config.Config
 	variation string
 	reason    string
+	stale     bool
+	trace     *Trace
 }
 
 func NewExperimentDecision(variation string, reason string, config config.Config) ExperimentDecision {
@@ -27,6 +29,24 @@ func (d ExperimentDecision) Reason() string {
 	return d.reason
 }
 
+func (d ExperimentDecision) Trace() *Trace {
+	return d.trace
+}
+
+func (d ExperimentDecision) WithTrace(trace *Trace) ExperimentDecision {
+	d.trace = trace
+	return d
+}
+
+func (d ExperimentDecision) IsStale() bool {
+	return d.stale
+}
+
+func (d ExperimentDecision) WithStale(stale bool) ExperimentDecision {
+	d.stale = stale
+	return d
+}
+
 func (d ExperimentDecision) String() string {
 	return fmt.Sprintf("ExperimentDecision(variation=%s, reason=%s, config=%s)", d.Variation(), d.Reason(), d.Config)
 }
@@ -35,6 +55,8 @@ type FeatureFlagDecision struct {
 	config.Config
 	isOn   bool
 	reason string
+	stale  bool
+	trace  *Trace
 }
 
 func NewFeatureFlagDecision(isOn bool, reason string, config config.Config) FeatureFlagDecision {
@@ -53,6 +75,24 @@ func (d FeatureFlagDecision) Reason() string {
 	return d.reason
 }
 
+func (d FeatureFlagDecision) Trace() *Trace {
+	return d.trace
+}
+
+func (d FeatureFlagDecision) WithTrace(trace *Trace) FeatureFlagDecision {
+	d.trace = trace
+	return d
+}
+
+func (d FeatureFlagDecision) IsStale() bool {
+	return d.stale
+}
+
+func (d FeatureFlagDecision) WithStale(stale bool) FeatureFlagDecision {
+	d.stale = stale
+	return d
+}
+
 func (d FeatureFlagDecision) String() string {
 	return fmt.Sprintf("FeatureFlagDecision(isOn=%t, reason=%s, config=%s)", d.IsOn(), d.Reason(), d.Config)
 }
@@ -60,6 +100,7 @@ func (d FeatureFlagDecision) String() string {
 type RemoteConfigDecision struct {
 	value  interface{}
 	reason string
+	stale  bool
 }
 
 func NewRemoteConfigDecision(value interface{}, reason string) RemoteConfigDecision {
@@ -77,6 +118,15 @@ func (d RemoteConfigDecision) Reason() string {
 	return d.reason
 }
 
+func (d RemoteConfigDecision) IsStale() bool {
+	return d.stale
+}
+
+func (d RemoteConfigDecision) WithStale(stale bool) RemoteConfigDecision {
+	d.stale = stale
+	return d
+}
+
 func (d RemoteConfigDecision) String() string {
 	return fmt.Sprintf("RemoteConfigDecision(value=%s, reason=%s)", d.Value(), d.Reason())
 }
@@ -90,6 +140,7 @@ const (
 	ReasonExperimentPaused               = "EXPERIMENT_PAUSED"
 	ReasonExperimentCompleted            = "EXPERIMENT_COMPLETED"
 	ReasonOverridden                     = "OVERRIDDEN"
+	ReasonLocalOverridden                = "LOCAL_OVERRIDDEN"
 	ReasonTrafficNotAllocated            = "TRAFFIC_NOT_ALLOCATED"
 	ReasonNotInMutualExclusionExperiment = "NOT_IN_MUTUAL_EXCLUSION_EXPERIMENT"
 	ReasonIdentifierNotFound             = "IDENTIFIER_NOT_FOUND"
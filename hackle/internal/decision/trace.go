@@ -0,0 +1,103 @@
+package decision
+
+// Trace records the evaluation flow steps of a decision.
+// Steps of target experiments evaluated along the way are recorded in evaluation order.
+// A nil *Trace records nothing.
+type Trace struct {
+	Steps []TraceStep
+	stack []int
+}
+
+// TraceStep is a flow step of an experiment evaluation.
+// Reason is empty if the step passed the evaluation on to the next flow,
+// and TargetRuleIndex is set only if a target rule of a feature flag matched.
+type TraceStep struct {
+	ExperimentID    int64
+	ExperimentKey   int64
+	Flow            string
+	Reason          string
+	TargetRuleIndex *int
+	Conditions      []TraceCondition
+	Slots           []TraceSlot
+	passed          bool
+}
+
+type TraceCondition struct {
+	KeyType string
+	Key     string
+	Matched bool
+}
+
+type TraceSlot struct {
+	BucketID   int64
+	SlotNumber int
+}
+
+const (
+	TraceFlowLocalOverride   = "LOCAL_OVERRIDE"
+	TraceFlowOverride        = "OVERRIDE"
+	TraceFlowDraft           = "DRAFT"
+	TraceFlowPaused          = "PAUSED"
+	TraceFlowCompleted       = "COMPLETED"
+	TraceFlowTarget          = "TARGET"
+	TraceFlowContainer       = "CONTAINER"
+	TraceFlowIdentifier      = "IDENTIFIER"
+	TraceFlowTrafficAllocate = "TRAFFIC_ALLOCATE"
+	TraceFlowTargetRule      = "TARGET_RULE"
+	TraceFlowDefaultRule     = "DEFAULT_RULE"
+)
+
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+func (t *Trace) Enter(experimentID int64, experimentKey int64, flow string) int {
+	if t == nil {
+		return -1
+	}
+	if current, ok := t.current(); ok && current.ExperimentID == experimentID {
+		current.passed = true
+	}
+	t.Steps = append(t.Steps, TraceStep{ExperimentID: experimentID, ExperimentKey: experimentKey, Flow: flow})
+	index := len(t.Steps) - 1
+	t.stack = append(t.stack, index)
+	return index
+}
+
+func (t *Trace) Exit(index int, reason string) {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+	if index < 0 || index >= len(t.Steps) {
+		return
+	}
+	if !t.Steps[index].passed {
+		t.Steps[index].Reason = reason
+	}
+}
+
+func (t *Trace) Condition(keyType string, key string, matched bool) {
+	if current, ok := t.current(); ok {
+		current.Conditions = append(current.Conditions, TraceCondition{KeyType: keyType, Key: key, Matched: matched})
+	}
+}
+
+func (t *Trace) Slot(bucketID int64, slotNumber int) {
+	if current, ok := t.current(); ok {
+		current.Slots = append(current.Slots, TraceSlot{BucketID: bucketID, SlotNumber: slotNumber})
+	}
+}
+
+func (t *Trace) TargetRule(index int) {
+	if current, ok := t.current(); ok {
+		current.TargetRuleIndex = &index
+	}
+}
+
+func (t *Trace) current() (*TraceStep, bool) {
+	if t == nil || len(t.stack) == 0 {
+		return nil, false
+	}
+	return &t.Steps[t.stack[len(t.stack)-1]], true
+}
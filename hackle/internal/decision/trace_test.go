@@ -0,0 +1,67 @@
+package decision
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+
+	t.Run("record flow steps", func(t *testing.T) {
+		trace := NewTrace()
+
+		override := trace.Enter(1, 10, TraceFlowOverride)
+		target := trace.Enter(1, 10, TraceFlowTarget)
+		trace.Condition("USER_PROPERTY", "age", true)
+
+		nested := trace.Enter(2, 20, TraceFlowTrafficAllocate)
+		trace.Slot(100, 42)
+		trace.Exit(nested, ReasonTrafficAllocated)
+
+		trace.Condition("AB_TEST", "20", false)
+		trace.Exit(target, ReasonNotInExperimentTarget)
+		trace.Exit(override, ReasonNotInExperimentTarget)
+
+		assert.Equal(t, 3, len(trace.Steps))
+
+		assert.Equal(t, TraceFlowOverride, trace.Steps[0].Flow)
+		assert.Equal(t, "", trace.Steps[0].Reason)
+
+		assert.Equal(t, TraceFlowTarget, trace.Steps[1].Flow)
+		assert.Equal(t, ReasonNotInExperimentTarget, trace.Steps[1].Reason)
+		assert.Equal(t, []TraceCondition{{KeyType: "USER_PROPERTY", Key: "age", Matched: true}, {KeyType: "AB_TEST", Key: "20", Matched: false}}, trace.Steps[1].Conditions)
+
+		assert.Equal(t, int64(2), trace.Steps[2].ExperimentID)
+		assert.Equal(t, int64(20), trace.Steps[2].ExperimentKey)
+		assert.Equal(t, ReasonTrafficAllocated, trace.Steps[2].Reason)
+		assert.Equal(t, []TraceSlot{{BucketID: 100, SlotNumber: 42}}, trace.Steps[2].Slots)
+	})
+
+	t.Run("record matched target rule", func(t *testing.T) {
+		trace := NewTrace()
+		index := trace.Enter(1, 10, TraceFlowTargetRule)
+		trace.TargetRule(2)
+		trace.Exit(index, ReasonTargetRuleMatch)
+
+		assert.Equal(t, 2, *trace.Steps[0].TargetRuleIndex)
+	})
+
+	t.Run("nil trace records nothing", func(t *testing.T) {
+		var trace *Trace
+		index := trace.Enter(1, 10, TraceFlowOverride)
+		trace.Condition("USER_PROPERTY", "age", true)
+		trace.Slot(100, 42)
+		trace.TargetRule(0)
+		trace.Exit(index, ReasonOverridden)
+
+		assert.Nil(t, trace)
+	})
+
+	t.Run("decision with trace", func(t *testing.T) {
+		trace := NewTrace()
+		assert.Nil(t, NewExperimentDecision("A", ReasonTrafficAllocated, config.Empty()).Trace())
+		assert.Equal(t, trace, NewExperimentDecision("A", ReasonTrafficAllocated, config.Empty()).WithTrace(trace).Trace())
+		assert.Equal(t, trace, NewFeatureFlagDecision(true, ReasonDefaultRule, config.Empty()).WithTrace(trace).Trace())
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 type Bucketer interface {
 	Bucketing(bucket model.Bucket, identifier string) (model.Slot, bool)
+	SlotNumber(bucket model.Bucket, identifier string) int
 }
 
 func NewBucketer() Bucketer {
@@ -19,8 +20,11 @@ type bucketer struct {
 }
 
 func (b *bucketer) Bucketing(bucket model.Bucket, identifier string) (model.Slot, bool) {
-	slotNumber := b.calculateSlotNumber(bucket.Seed, bucket.SlotSize, identifier)
-	return bucket.GetSlot(slotNumber)
+	return bucket.GetSlot(b.SlotNumber(bucket, identifier))
+}
+
+func (b *bucketer) SlotNumber(bucket model.Bucket, identifier string) int {
+	return b.calculateSlotNumber(bucket.Seed, bucket.SlotSize, identifier)
 }
 
 func (b *bucketer) calculateSlotNumber(seed int, slotSize int, value string) int {
@@ -8,6 +8,10 @@ type Hasher interface {
 	Hash(data string, seed int32) int32
 }
 
+func NewHasher() Hasher {
+	return &murmur3Hasher{}
+}
+
 type murmur3Hasher struct{}
 
 func (m *murmur3Hasher) Hash(data string, seed int32) int32 {
@@ -1,5 +1,7 @@
 package evaluator
 
+import "github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
+
 type Context interface {
 	Requests() []Request
 	Contains(request Request) bool
@@ -7,6 +9,7 @@ type Context interface {
 	RemoveRequest(request Request)
 	Evaluations() []Evaluation
 	AddEvaluation(evaluation Evaluation)
+	Trace() *decision.Trace
 }
 
 func NewContext() Context {
@@ -16,9 +19,18 @@ func NewContext() Context {
 	}
 }
 
+func NewTracingContext() Context {
+	return &context{
+		requests:    make([]Request, 0),
+		evaluations: make([]Evaluation, 0),
+		trace:       decision.NewTrace(),
+	}
+}
+
 type context struct {
 	requests    []Request
 	evaluations []Evaluation
+	trace       *decision.Trace
 }
 
 func (c *context) Requests() []Request {
@@ -59,3 +71,7 @@ func (c *context) Evaluations() []Evaluation {
 func (c *context) AddEvaluation(evaluation Evaluation) {
 	c.evaluations = append(c.evaluations, evaluation)
 }
+
+func (c *context) Trace() *decision.Trace {
+	return c.trace
+}
@@ -40,12 +40,13 @@ type targetRuleDeterminer struct {
 }
 
 func (d *targetRuleDeterminer) Determine(request Request, context evaluator.Context) (model.TargetRule, bool, error) {
-	for _, targetRule := range request.Experiment.TargetRules {
+	for i, targetRule := range request.Experiment.TargetRules {
 		matches, err := d.targetMatcher.Matches(request, context, targetRule.Target)
 		if err != nil {
 			return model.TargetRule{}, false, err
 		}
 		if matches {
+			context.Trace().TargetRule(i)
 			return targetRule, true, nil
 		}
 	}
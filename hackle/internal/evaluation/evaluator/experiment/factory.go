@@ -12,7 +12,7 @@ type EvaluationFlowFactory interface {
 	Get(experimentType model.ExperimentType) (flow.EvaluationFlow, error)
 }
 
-func NewFlowFactory(targetMatcher target.Matcher, bucketer bucketer.Bucketer) EvaluationFlowFactory {
+func NewFlowFactory(targetMatcher target.Matcher, bucketer bucketer.Bucketer, localOverrides LocalOverrides) EvaluationFlowFactory {
 
 	actionResolver := &actionResolver{bucketer: bucketer}
 	overrideResolver := &overrideResolver{targetMatcher, actionResolver}
@@ -21,6 +21,7 @@ func NewFlowFactory(targetMatcher target.Matcher, bucketer bucketer.Bucketer) Ev
 	targetRuleDeterminer := &targetRuleDeterminer{targetMatcher}
 
 	abTestFlow := flow.NewEvaluationFlow(
+		NewLocalOverrideEvaluator(localOverrides),
 		NewOverrideEvaluator(overrideResolver),
 		NewIdentifierEvaluator(),
 		NewContainerEvaluator(containerResolver),
@@ -32,6 +33,7 @@ func NewFlowFactory(targetMatcher target.Matcher, bucketer bucketer.Bucketer) Ev
 	)
 
 	featureFlagFlow := flow.NewEvaluationFlow(
+		NewLocalOverrideEvaluator(localOverrides),
 		NewDraftEvaluator(),
 		NewPausedEvaluator(),
 		NewCompletedEvaluator(),
@@ -21,9 +21,10 @@ func TestEvaluationFlowFactory_Get(t *testing.T) {
 	}
 
 	t.Run("AB_TEST", func(t *testing.T) {
-		factory := NewFlowFactory(nil, nil)
+		factory := NewFlowFactory(nil, nil, nil)
 		f, err := factory.Get(model.ExperimentTypeAbTest)
 		assert.Nil(t, err)
+		f = decisionWith(f, &LocalOverrideEvaluator{})
 		f = decisionWith(f, &OverrideEvaluator{})
 		f = decisionWith(f, &IdentifierEvaluator{})
 		f = decisionWith(f, &ContainerEvaluator{})
@@ -36,9 +37,10 @@ func TestEvaluationFlowFactory_Get(t *testing.T) {
 	})
 
 	t.Run("FEATURE_FLAG", func(t *testing.T) {
-		factory := NewFlowFactory(nil, nil)
+		factory := NewFlowFactory(nil, nil, nil)
 		f, err := factory.Get(model.ExperimentTypeFeatureFlag)
 		assert.Nil(t, err)
+		f = decisionWith(f, &LocalOverrideEvaluator{})
 		f = decisionWith(f, &DraftEvaluator{})
 		f = decisionWith(f, &PausedEvaluator{})
 		f = decisionWith(f, &CompletedEvaluator{})
@@ -50,7 +52,7 @@ func TestEvaluationFlowFactory_Get(t *testing.T) {
 	})
 
 	t.Run("unsupported type", func(t *testing.T) {
-		factory := NewFlowFactory(nil, nil)
+		factory := NewFlowFactory(nil, nil, nil)
 		_, err := factory.Get("INVALID")
 		assert.NotNil(t, err)
 		assert.Contains(t, err.Error(), "unsupported experiment type")
@@ -14,6 +14,7 @@ type flowEvaluator interface {
 
 type baseFlowEvaluator struct {
 	flowEvaluator
+	flow string
 }
 
 func (e *baseFlowEvaluator) Evaluate(request evaluator.Request, context evaluator.Context, nextFlow flow.EvaluationFlow) (evaluator.Evaluation, bool, error) {
@@ -21,10 +22,18 @@ func (e *baseFlowEvaluator) Evaluate(request evaluator.Request, context evaluato
 	if !ok {
 		return nil, false, fmt.Errorf("unsupported request: %T (expected: experiment.Request)", request)
 	}
+	trace := context.Trace()
+	index := trace.Enter(experimentRequest.Experiment.ID, experimentRequest.Experiment.Key, e.flow)
 	experimentEvaluation, ok, err := e.evaluate(experimentRequest, context, nextFlow)
 	if err != nil {
+		trace.Exit(index, "")
 		return nil, false, err
 	}
+	if ok {
+		trace.Exit(index, experimentEvaluation.Reason())
+	} else {
+		trace.Exit(index, "")
+	}
 	return experimentEvaluation, ok, nil
 }
 
@@ -44,13 +53,44 @@ func (e *baseFlowEvaluator) defaultEvaluation(request Request, context evaluator
 	return evaluation, true, nil
 }
 
+type LocalOverrideEvaluator struct {
+	*baseFlowEvaluator
+	overrides LocalOverrides
+}
+
+func NewLocalOverrideEvaluator(overrides LocalOverrides) *LocalOverrideEvaluator {
+	e := &LocalOverrideEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowLocalOverride}, overrides}
+	e.flowEvaluator = e
+	return e
+}
+
+func (e *LocalOverrideEvaluator) evaluate(request Request, context evaluator.Context, nextFlow flow.EvaluationFlow) (evaluator.Evaluation, bool, error) {
+	if e.overrides == nil {
+		return nextFlow.Evaluate(request, context)
+	}
+	experiment := request.Experiment
+	identifier, ok := request.user.Identifiers[experiment.IdentifierType]
+	if !ok {
+		return nextFlow.Evaluate(request, context)
+	}
+	variationKey, ok := e.overrides.Get(experiment.Type, experiment.Key, identifier)
+	if !ok {
+		return nextFlow.Evaluate(request, context)
+	}
+	variation, ok := experiment.GetVariationByKey(variationKey)
+	if !ok {
+		return nextFlow.Evaluate(request, context)
+	}
+	return e.evaluation(request, context, variation, decision.ReasonLocalOverridden)
+}
+
 type OverrideEvaluator struct {
 	*baseFlowEvaluator
 	overrideResolver OverrideResolver
 }
 
 func NewOverrideEvaluator(overrideResolver OverrideResolver) *OverrideEvaluator {
-	e := &OverrideEvaluator{&baseFlowEvaluator{}, overrideResolver}
+	e := &OverrideEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowOverride}, overrideResolver}
 	e.flowEvaluator = e
 	return e
 }
@@ -77,7 +117,7 @@ type DraftEvaluator struct {
 }
 
 func NewDraftEvaluator() *DraftEvaluator {
-	e := &DraftEvaluator{&baseFlowEvaluator{}}
+	e := &DraftEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowDraft}}
 	e.flowEvaluator = e
 	return e
 }
@@ -95,7 +135,7 @@ type PausedEvaluator struct {
 }
 
 func NewPausedEvaluator() *PausedEvaluator {
-	e := &PausedEvaluator{&baseFlowEvaluator{}}
+	e := &PausedEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowPaused}}
 	e.flowEvaluator = e
 	return e
 }
@@ -120,7 +160,7 @@ type CompletedEvaluator struct {
 }
 
 func NewCompletedEvaluator() *CompletedEvaluator {
-	e := &CompletedEvaluator{&baseFlowEvaluator{}}
+	e := &CompletedEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowCompleted}}
 	e.flowEvaluator = e
 	return e
 }
@@ -144,7 +184,7 @@ type TargetEvaluator struct {
 }
 
 func NewTargetEvaluator(determiner TargetDeterminer) *TargetEvaluator {
-	e := &TargetEvaluator{&baseFlowEvaluator{}, determiner}
+	e := &TargetEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowTarget}, determiner}
 	e.flowEvaluator = e
 	return e
 }
@@ -170,7 +210,7 @@ type TrafficAllocateEvaluator struct {
 }
 
 func NewTrafficAllocatedEvaluator(actionResolver ActionResolver) *TrafficAllocateEvaluator {
-	e := &TrafficAllocateEvaluator{&baseFlowEvaluator{}, actionResolver}
+	e := &TrafficAllocateEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowTrafficAllocate}, actionResolver}
 	e.flowEvaluator = e
 	return e
 }
@@ -184,7 +224,7 @@ func (e *TrafficAllocateEvaluator) evaluate(request Request, context evaluator.C
 		return nil, false, fmt.Errorf("experiment type must be AB_TEST [%d]", experiment.ID)
 	}
 	defaultRule := experiment.DefaultRule
-	variation, ok, err := e.actionResolver.Resolve(request, defaultRule)
+	variation, ok, err := e.actionResolver.Resolve(request, context, defaultRule)
 	if err != nil {
 		return nil, false, err
 	}
@@ -204,7 +244,7 @@ type TargetRuleEvaluator struct {
 }
 
 func NewTargetRuleEvaluator(determiner TargetRuleDeterminer, actionResolver ActionResolver) *TargetRuleEvaluator {
-	e := &TargetRuleEvaluator{&baseFlowEvaluator{}, determiner, actionResolver}
+	e := &TargetRuleEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowTargetRule}, determiner, actionResolver}
 	e.flowEvaluator = e
 	return e
 }
@@ -227,7 +267,7 @@ func (e *TargetRuleEvaluator) evaluate(request Request, context evaluator.Contex
 	if !ok {
 		return nextFlow.Evaluate(request, context)
 	}
-	variation, ok, err := e.actionResolver.Resolve(request, targetRule.Action)
+	variation, ok, err := e.actionResolver.Resolve(request, context, targetRule.Action)
 	if err != nil {
 		return nil, false, err
 	}
@@ -243,7 +283,7 @@ type DefaultRuleEvaluator struct {
 }
 
 func NewDefaultRuleEvaluator(actionResolver ActionResolver) *DefaultRuleEvaluator {
-	e := &DefaultRuleEvaluator{&baseFlowEvaluator{}, actionResolver}
+	e := &DefaultRuleEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowDefaultRule}, actionResolver}
 	e.flowEvaluator = e
 	return e
 }
@@ -259,7 +299,7 @@ func (e *DefaultRuleEvaluator) evaluate(request Request, context evaluator.Conte
 	if _, ok := request.user.Identifiers[experiment.IdentifierType]; !ok {
 		return e.defaultEvaluation(request, context, decision.ReasonDefaultRule)
 	}
-	variation, ok, err := e.actionResolver.Resolve(request, experiment.DefaultRule)
+	variation, ok, err := e.actionResolver.Resolve(request, context, experiment.DefaultRule)
 	if err != nil {
 		return nil, false, err
 	}
@@ -275,7 +315,7 @@ type ContainerEvaluator struct {
 }
 
 func NewContainerEvaluator(containerResolver ContainerResolver) *ContainerEvaluator {
-	e := &ContainerEvaluator{&baseFlowEvaluator{}, containerResolver}
+	e := &ContainerEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowContainer}, containerResolver}
 	e.flowEvaluator = e
 	return e
 }
@@ -291,7 +331,7 @@ func (e *ContainerEvaluator) evaluate(request Request, context evaluator.Context
 		return nil, false, fmt.Errorf("container [%d]", *experiment.ContainerID)
 	}
 
-	isUserInContainerGroup, err := e.containerResolver.IsUserInContainerGroup(request, container)
+	isUserInContainerGroup, err := e.containerResolver.IsUserInContainerGroup(request, context, container)
 	if err != nil {
 		return nil, false, err
 	}
@@ -307,7 +347,7 @@ type IdentifierEvaluator struct {
 }
 
 func NewIdentifierEvaluator() *IdentifierEvaluator {
-	e := &IdentifierEvaluator{&baseFlowEvaluator{}}
+	e := &IdentifierEvaluator{&baseFlowEvaluator{flow: decision.TraceFlowIdentifier}}
 	e.flowEvaluator = e
 	return e
 }
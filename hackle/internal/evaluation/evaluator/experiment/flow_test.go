@@ -28,7 +28,7 @@ func TestBaseFlowEvaluator_Evaluate(t *testing.T) {
 
 	t.Run("when error on flow evaluate then return error", func(t *testing.T) {
 		// given
-		sut := &baseFlowEvaluator{&mockFlowEvaluator{errors.New("failed to evaluate")}}
+		sut := &baseFlowEvaluator{flowEvaluator: &mockFlowEvaluator{errors.New("failed to evaluate")}}
 
 		// when
 		_, _, err := sut.Evaluate(Request{}, evaluator.NewContext(), flow.NewEvaluationFlow())
@@ -40,7 +40,7 @@ func TestBaseFlowEvaluator_Evaluate(t *testing.T) {
 	t.Run("when flow evaluated then return evaluated evaluation", func(t *testing.T) {
 		// given
 		evaluation := Evaluation{reason: "42"}
-		sut := &baseFlowEvaluator{&mockFlowEvaluator{evaluation}}
+		sut := &baseFlowEvaluator{flowEvaluator: &mockFlowEvaluator{evaluation}}
 
 		// when
 		actual, ok, err := sut.Evaluate(Request{}, evaluator.NewContext(), flow.NewEvaluationFlow())
@@ -109,6 +109,91 @@ func TestBaseEvaluator_evaluateDefault(t *testing.T) {
 	})
 }
 
+func TestLocalOverrideEvaluator_evaluate(t *testing.T) {
+
+	experiment := model.Experiment{
+		ID:             42,
+		Key:            7,
+		Type:           model.ExperimentTypeAbTest,
+		IdentifierType: "$id",
+		Variations:     []model.Variation{{ID: 320, Key: "A"}, {ID: 321, Key: "B"}},
+	}
+	request := Request{
+		user:       user.NewHackleUserBuilder().Identifier(user.IdentifierTypeID, "user").Build(),
+		Experiment: experiment,
+	}
+
+	t.Run("when not overridden then evaluate next flow", func(t *testing.T) {
+		// given
+		overrides := NewLocalOverrideStore()
+		overrides.Set(model.ExperimentTypeAbTest, 7, "other", "B")
+		overrides.Set(model.ExperimentTypeFeatureFlag, 7, "user", "B")
+		nextFlow := &mockFlow{returns: Evaluation{reason: "next_flow"}}
+
+		// when
+		sut := NewLocalOverrideEvaluator(overrides)
+		evaluation, ok, err := sut.evaluate(request, evaluator.NewContext(), nextFlow)
+
+		// then
+		assert.Equal(t, Evaluation{reason: "next_flow"}, evaluation)
+		assert.Equal(t, true, ok)
+		assert.Nil(t, err)
+	})
+
+	t.Run("when overridden variation not found then evaluate next flow", func(t *testing.T) {
+		// given
+		overrides := NewLocalOverrideStore()
+		overrides.Set(model.ExperimentTypeAbTest, 7, "user", "C")
+		nextFlow := &mockFlow{returns: Evaluation{reason: "next_flow"}}
+
+		// when
+		sut := NewLocalOverrideEvaluator(overrides)
+		evaluation, ok, err := sut.evaluate(request, evaluator.NewContext(), nextFlow)
+
+		// then
+		assert.Equal(t, Evaluation{reason: "next_flow"}, evaluation)
+		assert.Equal(t, true, ok)
+		assert.Nil(t, err)
+	})
+
+	t.Run("when overrides is nil then evaluate next flow", func(t *testing.T) {
+		// given
+		nextFlow := &mockFlow{returns: Evaluation{reason: "next_flow"}}
+
+		// when
+		sut := NewLocalOverrideEvaluator(nil)
+		evaluation, ok, err := sut.evaluate(request, evaluator.NewContext(), nextFlow)
+
+		// then
+		assert.Equal(t, Evaluation{reason: "next_flow"}, evaluation)
+		assert.Equal(t, true, ok)
+		assert.Nil(t, err)
+	})
+
+	t.Run("when overridden then return overridden variation with local overridden reason", func(t *testing.T) {
+		// given
+		overrides := NewLocalOverrideStore()
+		overrides.Set(model.ExperimentTypeAbTest, 7, "user", "B")
+		nextFlow := &mockFlow{returns: Evaluation{reason: "next_flow"}}
+
+		// when
+		sut := NewLocalOverrideEvaluator(overrides)
+		evaluation, ok, err := sut.evaluate(request, evaluator.NewContext(), nextFlow)
+
+		// then
+		assert.Equal(t, Evaluation{
+			reason:            "LOCAL_OVERRIDDEN",
+			targetEvaluations: make([]evaluator.Evaluation, 0),
+			Experiment:        experiment,
+			VariationID:       ref.Int64(321),
+			VariationKey:      "B",
+			config:            nil,
+		}, evaluation)
+		assert.Equal(t, true, ok)
+		assert.Nil(t, err)
+	})
+}
+
 func TestOverrideEvaluator_evaluate(t *testing.T) {
 
 	t.Run("when error on override resolve then return error", func(t *testing.T) {
@@ -1530,7 +1615,7 @@ type mockActionResolver struct {
 	count   int
 }
 
-func (m *mockActionResolver) Resolve(request Request, action model.Action) (model.Variation, bool, error) {
+func (m *mockActionResolver) Resolve(request Request, context evaluator.Context, action model.Action) (model.Variation, bool, error) {
 	m.count++
 	switch r := m.returns.(type) {
 	case model.Variation:
@@ -1561,7 +1646,7 @@ type mockContainerResolver struct {
 	returns interface{}
 }
 
-func (m *mockContainerResolver) IsUserInContainerGroup(request Request, container model.Container) (bool, error) {
+func (m *mockContainerResolver) IsUserInContainerGroup(request Request, context evaluator.Context, container model.Container) (bool, error) {
 	switch r := m.returns.(type) {
 	case bool:
 		return r, nil
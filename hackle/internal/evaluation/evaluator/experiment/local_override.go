@@ -0,0 +1,52 @@
+package experiment
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"sync"
+)
+
+type LocalOverrides interface {
+	Get(experimentType model.ExperimentType, experimentKey int64, identifier string) (variationKey string, ok bool)
+}
+
+type localOverrideKey struct {
+	experimentType model.ExperimentType
+	experimentKey  int64
+	identifier     string
+}
+
+type LocalOverrideStore struct {
+	overrides map[localOverrideKey]string
+	mu        sync.RWMutex
+}
+
+func NewLocalOverrideStore() *LocalOverrideStore {
+	return &LocalOverrideStore{
+		overrides: make(map[localOverrideKey]string),
+	}
+}
+
+func (s *LocalOverrideStore) Get(experimentType model.ExperimentType, experimentKey int64, identifier string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	variationKey, ok := s.overrides[localOverrideKey{experimentType, experimentKey, identifier}]
+	return variationKey, ok
+}
+
+func (s *LocalOverrideStore) Set(experimentType model.ExperimentType, experimentKey int64, identifier string, variationKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[localOverrideKey{experimentType, experimentKey, identifier}] = variationKey
+}
+
+func (s *LocalOverrideStore) Remove(experimentType model.ExperimentType, experimentKey int64, identifier string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, localOverrideKey{experimentType, experimentKey, identifier})
+}
+
+func (s *LocalOverrideStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = make(map[localOverrideKey]string)
+}
@@ -0,0 +1,35 @@
+package experiment
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLocalOverrideStore(t *testing.T) {
+	sut := NewLocalOverrideStore()
+
+	sut.Set(model.ExperimentTypeAbTest, 42, "user", "B")
+	sut.Set(model.ExperimentTypeFeatureFlag, 42, "user", "A")
+
+	variationKey, ok := sut.Get(model.ExperimentTypeAbTest, 42, "user")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "B", variationKey)
+
+	variationKey, ok = sut.Get(model.ExperimentTypeFeatureFlag, 42, "user")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "A", variationKey)
+
+	_, ok = sut.Get(model.ExperimentTypeAbTest, 42, "other")
+	assert.Equal(t, false, ok)
+
+	sut.Remove(model.ExperimentTypeAbTest, 42, "user")
+	_, ok = sut.Get(model.ExperimentTypeAbTest, 42, "user")
+	assert.Equal(t, false, ok)
+	_, ok = sut.Get(model.ExperimentTypeFeatureFlag, 42, "user")
+	assert.Equal(t, true, ok)
+
+	sut.Clear()
+	_, ok = sut.Get(model.ExperimentTypeFeatureFlag, 42, "user")
+	assert.Equal(t, false, ok)
+}
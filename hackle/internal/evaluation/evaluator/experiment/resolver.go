@@ -9,19 +9,19 @@ import (
 )
 
 type ActionResolver interface {
-	Resolve(request Request, action model.Action) (model.Variation, bool, error)
+	Resolve(request Request, context evaluator.Context, action model.Action) (model.Variation, bool, error)
 }
 
 type actionResolver struct {
 	bucketer bucketer.Bucketer
 }
 
-func (r *actionResolver) Resolve(request Request, action model.Action) (model.Variation, bool, error) {
+func (r *actionResolver) Resolve(request Request, context evaluator.Context, action model.Action) (model.Variation, bool, error) {
 	switch action.Type {
 	case model.ActionTypeVariation:
 		return r.resolveVariation(request, action)
 	case model.ActionTypeBucket:
-		return r.resolveBucket(request, action)
+		return r.resolveBucket(request, context, action)
 	}
 	return model.Variation{}, false, fmt.Errorf("unsupported action type [%s]", action.Type)
 }
@@ -38,7 +38,7 @@ func (r *actionResolver) resolveVariation(request Request, action model.Action)
 	return variation, true, nil
 }
 
-func (r *actionResolver) resolveBucket(request Request, action model.Action) (model.Variation, bool, error) {
+func (r *actionResolver) resolveBucket(request Request, context evaluator.Context, action model.Action) (model.Variation, bool, error) {
 	bucketID := action.BucketID
 	if bucketID == nil {
 		return model.Variation{}, false, fmt.Errorf("action bucket [%d]", request.Experiment.ID)
@@ -51,6 +51,7 @@ func (r *actionResolver) resolveBucket(request Request, action model.Action) (mo
 	if !ok {
 		return model.Variation{}, false, nil
 	}
+	traceSlot(context, r.bucketer, bucket, identifier)
 	slot, ok := r.bucketer.Bucketing(bucket, identifier)
 	if !ok {
 		return model.Variation{}, false, nil
@@ -95,7 +96,7 @@ func (r *overrideResolver) resolveSegmentOverride(request Request, context evalu
 			return model.Variation{}, false, err
 		}
 		if matches {
-			return r.actionResolver.Resolve(request, overriddenRule.Action)
+			return r.actionResolver.Resolve(request, context, overriddenRule.Action)
 		}
 
 	}
@@ -103,14 +104,14 @@ func (r *overrideResolver) resolveSegmentOverride(request Request, context evalu
 }
 
 type ContainerResolver interface {
-	IsUserInContainerGroup(request Request, container model.Container) (bool, error)
+	IsUserInContainerGroup(request Request, context evaluator.Context, container model.Container) (bool, error)
 }
 
 type containerResolver struct {
 	bucketer bucketer.Bucketer
 }
 
-func (r *containerResolver) IsUserInContainerGroup(request Request, container model.Container) (bool, error) {
+func (r *containerResolver) IsUserInContainerGroup(request Request, context evaluator.Context, container model.Container) (bool, error) {
 
 	experiment := request.Experiment
 
@@ -123,6 +124,7 @@ func (r *containerResolver) IsUserInContainerGroup(request Request, container mo
 	if !ok {
 		return false, fmt.Errorf("bucket [%d]", container.BucketID)
 	}
+	traceSlot(context, r.bucketer, bucket, identifier)
 
 	slot, ok := r.bucketer.Bucketing(bucket, identifier)
 	if !ok {
@@ -141,3 +143,9 @@ func (r *containerResolver) IsUserInContainerGroup(request Request, container mo
 	}
 	return false, nil
 }
+
+func traceSlot(context evaluator.Context, bucketer bucketer.Bucketer, bucket model.Bucket, identifier string) {
+	if trace := context.Trace(); trace != nil {
+		trace.Slot(bucket.ID, bucketer.SlotNumber(bucket, identifier))
+	}
+}
@@ -267,7 +267,7 @@ func TestActionResolver_resolve(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			sut := &actionResolver{bucketer: tc.fields.bucketer}
-			variation, ok, err := sut.Resolve(tc.args.request, tc.args.action)
+			variation, ok, err := sut.Resolve(tc.args.request, evaluator.NewContext(), tc.args.action)
 			assert.Equal(t, tc.expected.variation, variation)
 			assert.Equal(t, tc.expected.ok, ok)
 			assert.Equal(t, tc.expected.err, err)
@@ -672,7 +672,7 @@ func TestContainerResolver_IsUserInContainerGroup(t *testing.T) {
 			sut := &containerResolver{
 				bucketer: tc.fields.bucketer,
 			}
-			ok, err := sut.IsUserInContainerGroup(tc.args.request, tc.args.container)
+			ok, err := sut.IsUserInContainerGroup(tc.args.request, evaluator.NewContext(), tc.args.container)
 			assert.Equal(t, tc.expected.ok, ok)
 			assert.Equal(t, tc.expected.err, err)
 		})
@@ -691,3 +691,7 @@ func (m *mockBucketer) Bucketing(bucket model.Bucket, identifier string) (model.
 	}
 	return model.Slot{}, false
 }
+
+func (m *mockBucketer) SlotNumber(bucket model.Bucket, identifier string) int {
+	return 0
+}
@@ -339,6 +339,10 @@ func (m *mockBucketer) Bucketing(bucket model.Bucket, identifier string) (model.
 	return model.Slot{}, false
 }
 
+func (m *mockBucketer) SlotNumber(bucket model.Bucket, identifier string) int {
+	return 0
+}
+
 type mockTargetMatcher struct {
 	returns []interface{}
 	count   int
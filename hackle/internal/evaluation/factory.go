@@ -15,14 +15,14 @@ import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 )
 
-func NewEvaluators() (experiment.Evaluator, remoteconfig.Evaluator) {
+func NewEvaluators(localOverrides experiment.LocalOverrides) (experiment.Evaluator, remoteconfig.Evaluator) {
 
 	delegatingEvaluator := delegating.NewEvaluator()
 
 	targetMatcher := NewTargetMatcher(delegatingEvaluator)
 	buckter := bucketer.NewBucketer()
 
-	experimentEvaluator := experiment.NewEvaluator(experiment.NewFlowFactory(targetMatcher, buckter))
+	experimentEvaluator := experiment.NewEvaluator(experiment.NewFlowFactory(targetMatcher, buckter, localOverrides))
 	delegatingEvaluator.Add(experimentEvaluator)
 
 	remoteConfigEvaluator := remoteconfig.NewEvaluator(remoteconfig.NewTargetRuleDeterminer(targetMatcher, buckter))
@@ -1,12 +1,13 @@
 package evaluation
 
 import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/evaluator/experiment"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
 func TestNewEvaluators(t *testing.T) {
-	experimentEvaluator, remoteConfigEvaluator := NewEvaluators()
+	experimentEvaluator, remoteConfigEvaluator := NewEvaluators(experiment.NewLocalOverrideStore())
 	assert.NotNil(t, experimentEvaluator)
 	assert.NotNil(t, remoteConfigEvaluator)
 }
@@ -83,6 +83,7 @@ func getEvaluation(context evaluator.Context, exp model.Experiment) (evaluator.E
 }
 
 var abtestMatchedReasons = []string{
+	decision.ReasonLocalOverridden,
 	decision.ReasonOverridden,
 	decision.ReasonTrafficAllocated,
 	decision.ReasonExperimentCompleted,
@@ -38,5 +38,10 @@ func (m *matcher) matches(request evaluator.Request, context evaluator.Context,
 	if err != nil {
 		return false, err
 	}
-	return conditionMatcher.Matches(request, context, condition)
+	matches, err := conditionMatcher.Matches(request, context, condition)
+	if err != nil {
+		return false, err
+	}
+	context.Trace().Condition(string(condition.Key.Type), condition.Key.Name, matches)
+	return matches, nil
 }
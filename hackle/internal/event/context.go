@@ -0,0 +1,41 @@
+package event
+
+import "context"
+
+type contextUserEvent struct {
+	UserEvent
+	ctx context.Context
+}
+
+func WithContext(ctx context.Context, userEvent UserEvent) UserEvent {
+	if ctx == nil || ctx == context.Background() {
+		return userEvent
+	}
+	switch e := userEvent.(type) {
+	case ExposureEvent:
+		e.UserEvent = contextUserEvent{UserEvent: e.UserEvent, ctx: ctx}
+		return e
+	case TrackEvent:
+		e.UserEvent = contextUserEvent{UserEvent: e.UserEvent, ctx: ctx}
+		return e
+	case RemoteConfigEvent:
+		e.UserEvent = contextUserEvent{UserEvent: e.UserEvent, ctx: ctx}
+		return e
+	}
+	return userEvent
+}
+
+func ContextOf(userEvent UserEvent) context.Context {
+	switch e := userEvent.(type) {
+	case ExposureEvent:
+		userEvent = e.UserEvent
+	case TrackEvent:
+		userEvent = e.UserEvent
+	case RemoteConfigEvent:
+		userEvent = e.UserEvent
+	}
+	if e, ok := userEvent.(contextUserEvent); ok {
+		return e.ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type contextKey struct{}
+
+func TestContext(t *testing.T) {
+
+	t.Run("attach context to user event", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+		exposureEvent := WithContext(ctx, exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1))
+		trackEvent := WithContext(ctx, track("a", "purchase"))
+		remoteConfigEvent := WithContext(ctx, RemoteConfigEvent{UserEvent: baseUserEvent{insertID: "rc"}})
+
+		assert.Equal(t, "value", ContextOf(exposureEvent).Value(contextKey{}))
+		assert.Equal(t, "value", ContextOf(trackEvent).Value(contextKey{}))
+		assert.Equal(t, "value", ContextOf(remoteConfigEvent).Value(contextKey{}))
+		assert.Equal(t, "purchase", trackEvent.(TrackEvent).Event.Key())
+		assert.Equal(t, "insert-a", trackEvent.InsertID())
+		assert.Equal(t, "a", trackEvent.User().Identifiers["$id"])
+		assert.Equal(t, "rc", remoteConfigEvent.InsertID())
+	})
+
+	t.Run("when no context then background", func(t *testing.T) {
+		assert.Equal(t, context.Background(), ContextOf(track("a", "purchase")))
+		assert.Equal(t, track("a", "purchase"), WithContext(context.Background(), track("a", "purchase")))
+		assert.Equal(t, context.Background(), ContextOf(baseUserEvent{}))
+	})
+}
@@ -0,0 +1,114 @@
+package event
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Deduplicator interface {
+	IsDuplicate(event UserEvent) bool
+}
+
+func NewExposureDeduplicator(interval time.Duration, maxSize int, clock clock.Clock) Deduplicator {
+	return &exposureDeduplicator{
+		intervalMillis: interval.Milliseconds(),
+		maxSize:        maxSize,
+		clock:          clock,
+		entries:        list.New(),
+		index:          make(map[string]*list.Element),
+	}
+}
+
+type exposureDeduplicator struct {
+	intervalMillis int64
+	maxSize        int
+	clock          clock.Clock
+	entries        *list.List
+	index          map[string]*list.Element
+	mu             sync.Mutex
+}
+
+type dedupEntry struct {
+	key       string
+	expiresAt int64
+}
+
+func (d *exposureDeduplicator) IsDuplicate(event UserEvent) bool {
+	exposure, ok := event.(ExposureEvent)
+	if !ok {
+		return false
+	}
+
+	key := exposureDedupKey(exposure)
+	now := d.clock.CurrentMillis()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if element, ok := d.index[key]; ok {
+		d.entries.MoveToFront(element)
+		entry := element.Value.(*dedupEntry)
+		if now < entry.expiresAt {
+			return true
+		}
+		entry.expiresAt = now + d.intervalMillis
+		return false
+	}
+
+	d.index[key] = d.entries.PushFront(&dedupEntry{key: key, expiresAt: now + d.intervalMillis})
+	for d.entries.Len() > d.maxSize {
+		oldest := d.entries.Back()
+		d.entries.Remove(oldest)
+		delete(d.index, oldest.Value.(*dedupEntry).key)
+	}
+	return false
+}
+
+func exposureDedupKey(event ExposureEvent) string {
+	identifiers := event.User().Identifiers
+	types := make([]string, 0, len(identifiers))
+	for identifierType := range identifiers {
+		types = append(types, identifierType)
+	}
+	sort.Strings(types)
+
+	var sb strings.Builder
+	for _, identifierType := range types {
+		sb.WriteString(fmt.Sprintf("%q=%q;", identifierType, identifiers[identifierType]))
+	}
+	sb.WriteString(fmt.Sprintf("%d;%q;%q;%d", event.Experiment.ID, event.VariationKey, event.DecisionReason, event.Experiment.ExecutionVersion))
+	return sb.String()
+}
+
+func NewDedupProcessor(processor Processor, deduplicator Deduplicator) Processor {
+	return &dedupProcessor{
+		Processor:    processor,
+		deduplicator: deduplicator,
+	}
+}
+
+type dedupProcessor struct {
+	Processor
+	deduplicator Deduplicator
+}
+
+func (p *dedupProcessor) Process(event UserEvent) {
+	if p.deduplicator.IsDuplicate(event) {
+		return
+	}
+	p.Processor.Process(event)
+}
+
+func (p *dedupProcessor) ProcessAll(events []UserEvent) int {
+	unique := make([]UserEvent, 0, len(events))
+	for _, event := range events {
+		if !p.deduplicator.IsDuplicate(event) {
+			unique = append(unique, event)
+		}
+	}
+	return p.Processor.ProcessAll(unique)
+}
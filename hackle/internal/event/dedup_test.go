@@ -0,0 +1,105 @@
+package event
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type stepClock struct {
+	millis int64
+}
+
+func (c *stepClock) CurrentMillis() int64 {
+	return c.millis
+}
+
+func (c *stepClock) Tick() int64 {
+	return c.millis * int64(time.Millisecond)
+}
+
+func exposure(id string, experimentID int64, variationKey string, reason string, executionVersion int) ExposureEvent {
+	return ExposureEvent{
+		UserEvent:      baseUserEvent{user: user.HackleUser{Identifiers: map[string]string{"$id": id, "$deviceId": "device"}}},
+		Experiment:     model.Experiment{ID: experimentID, ExecutionVersion: executionVersion},
+		VariationKey:   variationKey,
+		DecisionReason: reason,
+	}
+}
+
+func TestExposureDeduplicator(t *testing.T) {
+
+	t.Run("duplicate exposure within interval", func(t *testing.T) {
+		clock := &stepClock{}
+		sut := NewExposureDeduplicator(time.Second, 100, clock)
+
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		clock.millis = 999
+		assert.Equal(t, true, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		clock.millis = 1000
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, true, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+	})
+
+	t.Run("different key is not duplicate", func(t *testing.T) {
+		sut := NewExposureDeduplicator(time.Minute, 100, &stepClock{})
+
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("b", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 2, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "B", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "OVERRIDDEN", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 2)))
+	})
+
+	t.Run("identifier order does not matter", func(t *testing.T) {
+		sut := NewExposureDeduplicator(time.Minute, 100, &stepClock{})
+		first := exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)
+		second := exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)
+		second.UserEvent = baseUserEvent{user: user.HackleUser{Identifiers: map[string]string{"$deviceId": "device", "$id": "a"}}}
+
+		assert.Equal(t, false, sut.IsDuplicate(first))
+		assert.Equal(t, true, sut.IsDuplicate(second))
+	})
+
+	t.Run("when max size exceeded then evict least recently used", func(t *testing.T) {
+		sut := NewExposureDeduplicator(time.Minute, 2, &stepClock{})
+
+		assert.Equal(t, false, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("b", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, true, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("c", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+
+		assert.Equal(t, true, sut.IsDuplicate(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+		assert.Equal(t, false, sut.IsDuplicate(exposure("b", 1, "A", "TRAFFIC_ALLOCATED", 1)))
+	})
+
+	t.Run("other events are never duplicate", func(t *testing.T) {
+		sut := NewExposureDeduplicator(time.Minute, 100, &stepClock{})
+		track := TrackEvent{UserEvent: baseUserEvent{}, Event: event{key: "a"}}
+
+		assert.Equal(t, false, sut.IsDuplicate(track))
+		assert.Equal(t, false, sut.IsDuplicate(track))
+	})
+}
+
+func TestDedupProcessor(t *testing.T) {
+	delegate := &mockProcessor{}
+	sut := NewDedupProcessor(delegate, NewExposureDeduplicator(time.Minute, 100, &stepClock{}))
+
+	sut.Process(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1))
+	sut.Process(exposure("a", 1, "A", "TRAFFIC_ALLOCATED", 1))
+	sut.Process(exposure("b", 1, "A", "TRAFFIC_ALLOCATED", 1))
+	accepted := sut.ProcessAll([]UserEvent{
+		exposure("b", 1, "A", "TRAFFIC_ALLOCATED", 1),
+		exposure("c", 1, "A", "TRAFFIC_ALLOCATED", 1),
+		exposure("c", 1, "A", "TRAFFIC_ALLOCATED", 1),
+	})
+	sut.Close()
+
+	assert.Equal(t, 1, accepted)
+	assert.Equal(t, 3, len(delegate.processed))
+	assert.Equal(t, true, delegate.closed)
+}
@@ -0,0 +1,156 @@
+package event
+
+import (
+	"sync"
+)
+
+// dispatchQueue sends queued batches on at most maxConcurrency goroutines.
+// When maxQueued batches are already waiting, a new batch is rejected and its events are counted as dropped.
+// A batch is in flight until send calls done, which may happen after the worker has moved on, e.g. while waiting to retry.
+type dispatchQueue struct {
+	maxConcurrency int
+	maxQueued      int
+	send           func(userEvents []UserEvent, done func(err error))
+	reject         func(userEvents []UserEvent) error
+	wg             *sync.WaitGroup
+	batches        sync.WaitGroup
+	jobs           []dispatchJob
+	resumes        []func()
+	workers        int
+	inFlight       int
+	dropped        int64
+	mu             sync.Mutex
+}
+
+type dispatchJob struct {
+	userEvents []UserEvent
+	done       func(err error)
+	resume     func()
+}
+
+func newDispatchQueue(
+	maxConcurrency int,
+	maxQueued int,
+	send func(userEvents []UserEvent, done func(err error)),
+	reject func(userEvents []UserEvent) error,
+) *dispatchQueue {
+	return &dispatchQueue{
+		maxConcurrency: maxConcurrency,
+		maxQueued:      maxQueued,
+		send:           send,
+		reject:         reject,
+		wg:             &sync.WaitGroup{},
+	}
+}
+
+// sendNow adapts a synchronous send to the dispatchQueue.
+func sendNow(send func(userEvents []UserEvent) error) func(userEvents []UserEvent, done func(err error)) {
+	return func(userEvents []UserEvent, done func(err error)) {
+		done(send(userEvents))
+	}
+}
+
+func (q *dispatchQueue) offer(userEvents []UserEvent, done func(err error)) {
+	q.mu.Lock()
+	if q.maxQueued > 0 && len(q.jobs) >= q.maxQueued {
+		q.dropped += int64(len(userEvents))
+		q.mu.Unlock()
+		err := q.reject(userEvents)
+		if done != nil {
+			done(err)
+		}
+		return
+	}
+	q.batches.Add(1)
+	q.jobs = append(q.jobs, dispatchJob{userEvents: userEvents, done: done})
+	q.startWorker()
+	q.mu.Unlock()
+}
+
+// resume runs the continuation of an in-flight batch on a worker before any queued batch.
+// It is not limited by maxQueued, as the batch was already accepted.
+func (q *dispatchQueue) resume(task func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resumes = append(q.resumes, task)
+	q.startWorker()
+}
+
+func (q *dispatchQueue) startWorker() {
+	if q.workers < q.maxConcurrency {
+		q.workers++
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+func (q *dispatchQueue) work() {
+	defer q.wg.Done()
+	for {
+		job, ok := q.nextJob()
+		if !ok {
+			return
+		}
+		if job.resume != nil {
+			job.resume()
+			continue
+		}
+		q.send(job.userEvents, q.complete(job))
+	}
+}
+
+func (q *dispatchQueue) nextJob() (dispatchJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.resumes) > 0 {
+		task := q.resumes[0]
+		q.resumes[0] = nil
+		q.resumes = q.resumes[1:]
+		return dispatchJob{resume: task}, true
+	}
+	if len(q.jobs) == 0 {
+		q.workers--
+		return dispatchJob{}, false
+	}
+	job := q.jobs[0]
+	q.jobs[0] = dispatchJob{}
+	q.jobs = q.jobs[1:]
+	q.inFlight++
+	return job, true
+}
+
+func (q *dispatchQueue) complete(job dispatchJob) func(err error) {
+	return func(err error) {
+		q.mu.Lock()
+		q.inFlight--
+		q.mu.Unlock()
+		if job.done != nil {
+			job.done(err)
+		}
+		q.batches.Done()
+	}
+}
+
+func (q *dispatchQueue) inFlightCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inFlight
+}
+
+func (q *dispatchQueue) queuedCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+func (q *dispatchQueue) droppedCount() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// wait blocks until every accepted batch is done and the workers exited.
+func (q *dispatchQueue) wait() {
+	q.batches.Wait()
+	q.wg.Wait()
+}
@@ -2,73 +2,247 @@ package event
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	nethttp "net/http"
 	"sync"
+	"time"
 )
 
 type Dispatcher interface {
-	Dispatch(userEvents []UserEvent)
+	Dispatch(userEvents []UserEvent, done func(err error))
+	InFlightCount() int
+	QueuedCount() int
+	DroppedCount() int64
 	Close()
 }
 
-func NewDispatcher(eventUrl string, httpClient http.Client) Dispatcher {
-	return &dispatcher{
-		url:        eventUrl + "/api/v2/events",
-		httpClient: httpClient,
-		wg:         &sync.WaitGroup{},
+type DeadLetterHandler func(userEvents []UserEvent)
+
+// DeadLetteredError is returned for events that failed to dispatch and were passed to the DeadLetterHandler.
+type DeadLetteredError struct {
+	Err error
+}
+
+func (e *DeadLetteredError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeadLetteredError) Unwrap() error {
+	return e.Err
+}
+
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxInFlight    int
+}
+
+func NewDispatcher(
+	eventUrl string,
+	httpClient http.Client,
+	maxConcurrency int,
+	maxQueued int,
+	compression bool,
+	maxPayloadBytes int,
+	retryPolicy RetryPolicy,
+	deadLetterHandler DeadLetterHandler,
+) Dispatcher {
+	d := &dispatcher{
+		url:               eventUrl + "/api/v2/events",
+		httpClient:        httpClient,
+		compression:       compression,
+		maxPayloadBytes:   maxPayloadBytes,
+		retryPolicy:       retryPolicy,
+		deadLetterHandler: deadLetterHandler,
+		done:              make(chan struct{}),
 	}
+	d.queue = newDispatchQueue(maxConcurrency, maxQueued, d.dispatch, d.reject)
+	return d
 }
 
 type dispatcher struct {
-	url        string
-	httpClient http.Client
-	wg         *sync.WaitGroup
+	url               string
+	httpClient        http.Client
+	queue             *dispatchQueue
+	compression       bool
+	maxPayloadBytes   int
+	retryPolicy       RetryPolicy
+	deadLetterHandler DeadLetterHandler
+	done              chan struct{}
+	closeOnce         sync.Once
+	retrying          int
+	mu                sync.Mutex
 }
 
-func (d *dispatcher) Dispatch(userEvents []UserEvent) {
-	d.wg.Add(1)
-	go func() {
-		defer d.wg.Done()
-		err := d.dispatch(userEvents)
+func (d *dispatcher) Dispatch(userEvents []UserEvent, done func(err error)) {
+	d.queue.offer(userEvents, done)
+}
+
+func (d *dispatcher) InFlightCount() int {
+	return d.queue.inFlightCount()
+}
+
+func (d *dispatcher) QueuedCount() int {
+	return d.queue.queuedCount()
+}
+
+func (d *dispatcher) DroppedCount() int64 {
+	return d.queue.droppedCount()
+}
+
+func (d *dispatcher) reject(userEvents []UserEvent) error {
+	return d.deadLetter(userEvents, errors.New("event dispatch queue is full"))
+}
+
+type payload struct {
+	userEvents []UserEvent
+	body       []byte
+}
+
+func (d *dispatcher) dispatch(userEvents []UserEvent, done func(err error)) {
+	payloads, err := d.encode(userEvents)
+	if err != nil {
+		done(d.deadLetter(userEvents, err))
+		return
+	}
+
+	var mu sync.Mutex
+	remaining := len(payloads)
+	var dispatchErr error
+	for _, p := range payloads {
+		d.dispatchPayload(p, func(err error) {
+			mu.Lock()
+			remaining--
+			if err != nil && dispatchErr == nil {
+				dispatchErr = err
+			}
+			completed := remaining == 0
+			mu.Unlock()
+			if completed {
+				done(dispatchErr)
+			}
+		})
+	}
+}
+
+func (d *dispatcher) encode(userEvents []UserEvent) ([]payload, error) {
+	body, err := json.Marshal(NewPayloadDTO(userEvents))
+	if err != nil {
+		return nil, err
+	}
+	if d.maxPayloadBytes > 0 && len(body) > d.maxPayloadBytes {
+		if len(userEvents) > 1 {
+			mid := len(userEvents) / 2
+			head, err := d.encode(userEvents[:mid])
+			if err != nil {
+				return nil, err
+			}
+			tail, err := d.encode(userEvents[mid:])
+			if err != nil {
+				return nil, err
+			}
+			return append(head, tail...), nil
+		}
+		logger.Warn("Event payload size %d bytes exceeds %d bytes.", len(body), d.maxPayloadBytes)
+	}
+	if d.compression {
+		body, err = compress(body)
 		if err != nil {
-			logger.Error("Failed to dispatch events: %v", err)
+			return nil, err
 		}
+	}
+	return []payload{{userEvents: userEvents, body: body}}, nil
+}
+
+func compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(body)
+	if e := writer.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *dispatcher) dispatchPayload(p payload, done func(err error)) {
+	retryable, err := d.send(p.body)
+	if err == nil {
+		done(nil)
+		return
+	}
+	if !retryable || d.retryPolicy.MaxRetries <= 0 {
+		done(d.deadLetter(p.userEvents, err))
+		return
+	}
+	if !d.acquireRetry() {
+		done(d.deadLetter(p.userEvents, errors.New("too many in-flight retries: "+err.Error())))
+		return
+	}
+
+	b := backoff.WithJitter(backoff.NewExponential(d.retryPolicy.InitialBackoff, d.retryPolicy.MaxBackoff, 2), 0.2)
+	d.retry(p, b, 1, err, func(err error) {
+		d.releaseRetry()
+		done(err)
+	})
+}
+
+// retry waits for the backoff without holding a worker, then sends the payload again on a worker.
+func (d *dispatcher) retry(p payload, b backoff.Backoff, attempt int, err error, done func(err error)) {
+	delay := retryDelay(b, err)
+	logger.Warn("Failed to dispatch events. Retrying in %s (%d/%d): %v", delay, attempt, d.retryPolicy.MaxRetries, err)
+	go func() {
+		closing := !d.wait(delay)
+		d.queue.resume(func() {
+			retryable, err := d.send(p.body)
+			if err == nil {
+				done(nil)
+				return
+			}
+			if !retryable || closing || attempt >= d.retryPolicy.MaxRetries {
+				done(d.deadLetter(p.userEvents, err))
+				return
+			}
+			d.retry(p, b, attempt+1, err, done)
+		})
 	}()
 }
 
-func (d *dispatcher) dispatch(userEvents []UserEvent) error {
-	req, err := d.createRequest(userEvents)
+func (d *dispatcher) send(body []byte) (retryable bool, err error) {
+	req, err := d.createRequest(body)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	res, err := d.httpClient.Execute(req)
 	if err != nil {
-		return err
+		return true, err
 	}
 
 	return d.handleResponse(res)
 }
 
-func (d *dispatcher) createRequest(userEvents []UserEvent) (*nethttp.Request, error) {
-	dto := NewPayloadDTO(userEvents)
-	body, err := json.Marshal(dto)
-	if err != nil {
-		return nil, err
-	}
-	req, err := nethttp.NewRequest(nethttp.MethodPost, d.url, bytes.NewBuffer(body))
+func (d *dispatcher) createRequest(body []byte) (*nethttp.Request, error) {
+	req, err := nethttp.NewRequest(nethttp.MethodPost, d.url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if d.compression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	return req, nil
 }
 
-func (d *dispatcher) handleResponse(res *nethttp.Response) error {
+func (d *dispatcher) handleResponse(res *nethttp.Response) (retryable bool, err error) {
 	defer func() {
 		e := res.Body.Close()
 		if e != nil {
@@ -77,14 +251,75 @@ func (d *dispatcher) handleResponse(res *nethttp.Response) error {
 	}()
 
 	if !http.IsSuccessful(res) {
-		return fmt.Errorf("http status code: %d", res.StatusCode)
+		return isRetryableStatus(res.StatusCode), http.NewResponseError(res)
 	}
 
-	return nil
+	return false, nil
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == nethttp.StatusRequestTimeout ||
+		statusCode == nethttp.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+func retryDelay(b backoff.Backoff, err error) time.Duration {
+	delay := b.Next()
+	var responseErr *http.ResponseError
+	if errors.As(err, &responseErr) && responseErr.RetryAfter > delay {
+		delay = responseErr.RetryAfter
+	}
+	return delay
+}
+
+func (d *dispatcher) wait(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.done:
+		return false
+	}
+}
+
+func (d *dispatcher) acquireRetry() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.retrying >= d.retryPolicy.MaxInFlight {
+		return false
+	}
+	d.retrying++
+	return true
+}
+
+func (d *dispatcher) releaseRetry() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.retrying--
+}
+
+func (d *dispatcher) deadLetter(userEvents []UserEvent, err error) error {
+	logger.Error("Failed to dispatch events: %v", err)
+	if d.deadLetterHandler != nil {
+		d.handleDeadLetter(userEvents)
+		return &DeadLetteredError{Err: err}
+	}
+	return err
+}
+
+func (d *dispatcher) handleDeadLetter(userEvents []UserEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in dead letter handler: %v", r)
+		}
+	}()
+	d.deadLetterHandler(userEvents)
 }
 
 func (d *dispatcher) Close() {
 	logger.Info("EventDispatcher shutting down.")
-	d.wg.Wait()
+	d.closeOnce.Do(func() { close(d.done) })
+	d.queue.wait()
 	logger.Info("EventDispatcher terminated.")
 }
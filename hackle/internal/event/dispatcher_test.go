@@ -2,20 +2,27 @@ package event
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"io/ioutil"
 	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 )
 
 func TestNewDispatcher(t *testing.T) {
-	d := NewDispatcher("localhost", &mockHttpClient{}).(*dispatcher)
+	d := NewDispatcher("localhost", &mockHttpClient{}, 1, 0, false, 0, RetryPolicy{}, nil).(*dispatcher)
 	assert.Equal(t, "localhost/api/v2/events", d.url)
 }
 
@@ -125,9 +132,10 @@ func Test_dispatcher_Dispatch(t *testing.T) {
 			d := &dispatcher{
 				url:        tt.fields.url,
 				httpClient: tt.fields.httpClient,
-				wg:         tt.fields.wg,
 			}
-			d.Dispatch(tt.args.userEvents)
+			d.queue = newDispatchQueue(1, 0, d.dispatch, d.reject)
+			d.queue.wg = tt.fields.wg
+			d.Dispatch(tt.args.userEvents, nil)
 			tt.assertion(tt.fields)
 		})
 	}
@@ -144,9 +152,9 @@ func Test_dispatcher_Close(t *testing.T) {
 			delay: 100 * time.Millisecond,
 		}
 
-		sut := NewDispatcher("localhost", httpClient)
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, RetryPolicy{}, nil)
 
-		sut.Dispatch(make([]UserEvent, 0))
+		sut.Dispatch(make([]UserEvent, 0), nil)
 
 		assert.Equal(t, false, httpClient.Called())
 		sut.Close()
@@ -154,6 +162,460 @@ func Test_dispatcher_Close(t *testing.T) {
 	})
 }
 
+func Test_dispatcher_retry(t *testing.T) {
+
+	event := NewTrackEvent(
+		model.EventType{ID: 42, Key: "my_key"},
+		event{key: "my_key"},
+		user.NewHackleUserBuilder().Identifier("$id", "id").Build(),
+		4200,
+	)
+	retryPolicy := RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		MaxInFlight:    10,
+	}
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		calls      int
+		deadLetter bool
+	}{
+		{name: "success", statuses: []int{200}, calls: 1, deadLetter: false},
+		{name: "retry server error then success", statuses: []int{500, 503, 200}, calls: 3, deadLetter: false},
+		{name: "retry network error then success", statuses: []int{0, 200}, calls: 2, deadLetter: false},
+		{name: "retry too many requests then success", statuses: []int{429, 200}, calls: 2, deadLetter: false},
+		{name: "not retryable status", statuses: []int{400}, calls: 1, deadLetter: true},
+		{name: "not retryable status while retrying", statuses: []int{500, 401}, calls: 2, deadLetter: true},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500}, calls: 4, deadLetter: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &mockStatusHttpClient{statuses: tt.statuses}
+			var deadLetters [][]UserEvent
+			sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, retryPolicy, func(userEvents []UserEvent) {
+				deadLetters = append(deadLetters, userEvents)
+			}).(*dispatcher)
+
+			err := dispatchAndWait(sut, []UserEvent{event})
+
+			assert.Equal(t, tt.calls, httpClient.Count())
+			if tt.deadLetter {
+				assert.IsType(t, &DeadLetteredError{}, err)
+				assert.Equal(t, [][]UserEvent{{event}}, deadLetters)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, len(deadLetters))
+			}
+		})
+	}
+
+	t.Run("when no retries then dead letter on first failure", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{500}}
+		var deadLetters [][]UserEvent
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, RetryPolicy{}, func(userEvents []UserEvent) {
+			deadLetters = append(deadLetters, userEvents)
+		})
+
+		sut.Dispatch([]UserEvent{event}, nil)
+		sut.Close()
+
+		assert.Equal(t, 1, httpClient.Count())
+		assert.Equal(t, 1, len(deadLetters))
+	})
+
+	t.Run("when too many in-flight retries then dead letter immediately", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{500, 500, 200}}
+		var mu sync.Mutex
+		var deadLetters [][]UserEvent
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, RetryPolicy{
+			MaxRetries:     1,
+			InitialBackoff: 200 * time.Millisecond,
+			MaxBackoff:     200 * time.Millisecond,
+			MaxInFlight:    1,
+		}, func(userEvents []UserEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			deadLetters = append(deadLetters, userEvents)
+		})
+
+		sut.Dispatch([]UserEvent{event}, nil)
+		time.Sleep(50 * time.Millisecond)
+		sut.Dispatch([]UserEvent{event}, nil)
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		assert.Equal(t, 1, len(deadLetters))
+		mu.Unlock()
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, 3, httpClient.Count())
+		sut.Close()
+	})
+
+	t.Run("when waiting to retry then do not hold a worker", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{500, 200, 200}}
+		sut := NewDispatcher("localhost", httpClient, 1, 0, false, 0, RetryPolicy{
+			MaxRetries:     1,
+			InitialBackoff: 200 * time.Millisecond,
+			MaxBackoff:     200 * time.Millisecond,
+			MaxInFlight:    10,
+		}, nil)
+
+		errs := make(chan error, 2)
+		sut.Dispatch([]UserEvent{event}, func(err error) { errs <- err })
+		time.Sleep(50 * time.Millisecond)
+		sut.Dispatch([]UserEvent{event}, func(err error) { errs <- err })
+
+		select {
+		case err := <-errs:
+			assert.Nil(t, err)
+		case <-time.After(100 * time.Millisecond):
+			assert.Fail(t, "expected second batch to be dispatched while first batch waits to retry")
+		}
+		assert.Equal(t, 2, httpClient.Count())
+		assert.Equal(t, 1, sut.InFlightCount())
+
+		assert.Nil(t, <-errs)
+		assert.Equal(t, 3, httpClient.Count())
+		sut.Close()
+	})
+
+	t.Run("when closed then stop waiting and try once more", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{500, 500}}
+		var deadLetters [][]UserEvent
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, RetryPolicy{
+			MaxRetries:     3,
+			InitialBackoff: time.Minute,
+			MaxBackoff:     time.Minute,
+			MaxInFlight:    10,
+		}, func(userEvents []UserEvent) {
+			deadLetters = append(deadLetters, userEvents)
+		})
+
+		sut.Dispatch([]UserEvent{event}, nil)
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		sut.Close()
+
+		assert.True(t, time.Since(start) < time.Second)
+		assert.Equal(t, 2, httpClient.Count())
+		assert.Equal(t, 1, len(deadLetters))
+	})
+
+	t.Run("when dispatch completed then call done", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{200, 400}}
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, retryPolicy, nil)
+
+		var results []error
+		var mu sync.Mutex
+		done := func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, err)
+		}
+		sut.Dispatch([]UserEvent{event}, done)
+		time.Sleep(50 * time.Millisecond)
+		sut.Dispatch([]UserEvent{event}, done)
+		sut.Close()
+
+		assert.Equal(t, 2, len(results))
+		assert.Nil(t, results[0])
+		assert.Equal(t, &http.ResponseError{StatusCode: 400}, results[1])
+	})
+
+	t.Run("when dead letter handler panics then recover", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{400}}
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, 0, retryPolicy, func(userEvents []UserEvent) {
+			panic("dead letter panic")
+		})
+
+		sut.Dispatch([]UserEvent{event}, nil)
+		sut.Close()
+
+		assert.Equal(t, 1, httpClient.Count())
+	})
+}
+
+func Test_dispatcher_concurrency(t *testing.T) {
+
+	t.Run("dispatch at most max concurrency batches at once", func(t *testing.T) {
+		httpClient := &mockConcurrencyHttpClient{delay: 50 * time.Millisecond}
+		sut := NewDispatcher("localhost", httpClient, 3, 0, false, 0, RetryPolicy{}, nil)
+
+		var mu sync.Mutex
+		completed := 0
+		for i := 0; i < 10; i++ {
+			sut.Dispatch([]UserEvent{}, func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				completed++
+			})
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 3, sut.InFlightCount())
+		assert.Equal(t, 7, sut.QueuedCount())
+
+		sut.Close()
+		assert.Equal(t, 10, completed)
+		assert.Equal(t, 3, httpClient.MaxConcurrent())
+		assert.Equal(t, 0, sut.InFlightCount())
+		assert.Equal(t, 0, sut.QueuedCount())
+	})
+
+	t.Run("workers exit when idle", func(t *testing.T) {
+		httpClient := &mockConcurrencyHttpClient{}
+		sut := NewDispatcher("localhost", httpClient, 3, 0, false, 0, RetryPolicy{}, nil).(*dispatcher)
+
+		sut.Dispatch([]UserEvent{}, nil)
+		time.Sleep(50 * time.Millisecond)
+
+		sut.queue.mu.Lock()
+		assert.Equal(t, 0, sut.queue.workers)
+		sut.queue.mu.Unlock()
+		sut.Close()
+	})
+
+	t.Run("when queue is full then dead letter and count dropped events", func(t *testing.T) {
+		httpClient := &mockConcurrencyHttpClient{delay: 50 * time.Millisecond}
+		var mu sync.Mutex
+		var deadLetters [][]UserEvent
+		sut := NewDispatcher("localhost", httpClient, 1, 2, false, 0, RetryPolicy{}, func(userEvents []UserEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			deadLetters = append(deadLetters, userEvents)
+		})
+
+		var errs []error
+		dispatch := func() {
+			sut.Dispatch([]UserEvent{track("a", "purchase"), track("b", "purchase")}, func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			})
+		}
+		dispatch()
+		time.Sleep(10 * time.Millisecond)
+		for i := 0; i < 4; i++ {
+			dispatch()
+		}
+
+		mu.Lock()
+		assert.Equal(t, 2, len(deadLetters))
+		assert.Equal(t, 2, len(errs))
+		assert.IsType(t, &DeadLetteredError{}, errs[0])
+		mu.Unlock()
+		assert.Equal(t, int64(4), sut.DroppedCount())
+		assert.Equal(t, 1, sut.InFlightCount())
+		assert.Equal(t, 2, sut.QueuedCount())
+
+		sut.Close()
+		assert.Equal(t, 5, len(errs))
+		assert.Equal(t, 1, httpClient.MaxConcurrent())
+	})
+}
+
+type mockConcurrencyHttpClient struct {
+	delay         time.Duration
+	concurrent    int
+	maxConcurrent int
+	mu            sync.Mutex
+}
+
+func (m *mockConcurrencyHttpClient) Execute(req *nethttp.Request) (*nethttp.Response, error) {
+	m.mu.Lock()
+	m.concurrent++
+	if m.concurrent > m.maxConcurrent {
+		m.maxConcurrent = m.concurrent
+	}
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	m.concurrent--
+	m.mu.Unlock()
+	return &nethttp.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader(make([]byte, 0))),
+	}, nil
+}
+
+func (m *mockConcurrencyHttpClient) MaxConcurrent() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.maxConcurrent
+}
+
+type payloadServer struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	payloads []PayloadDTO
+	encoding []string
+	sizes    []int
+}
+
+func newPayloadServer() *payloadServer {
+	s := &payloadServer{}
+	s.server = httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			reader = gzipReader
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		var dto PayloadDTO
+		if err := json.Unmarshal(body, &dto); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.payloads = append(s.payloads, dto)
+		s.encoding = append(s.encoding, r.Header.Get("Content-Encoding"))
+		s.sizes = append(s.sizes, len(body))
+		w.WriteHeader(200)
+	}))
+	return s
+}
+
+func (s *payloadServer) trackEventKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for _, payload := range s.payloads {
+		for _, track := range payload.TrackEvents {
+			keys = append(keys, track.EventTypeKey)
+		}
+	}
+	return keys
+}
+
+func Test_dispatcher_payload(t *testing.T) {
+
+	trackEvents := func(count int) []UserEvent {
+		var events []UserEvent
+		for i := 0; i < count; i++ {
+			key := fmt.Sprintf("event_%02d", i)
+			events = append(events, NewTrackEvent(
+				model.EventType{ID: int64(i), Key: key},
+				event{key: key, properties: map[string]interface{}{"description": strings.Repeat("x", 100)}},
+				user.NewHackleUserBuilder().Identifier("$id", "id").Build(),
+				4200,
+			))
+		}
+		return events
+	}
+	httpClient := http.NewClient(model.NewSdk("sdk_key"), clock.System, &nethttp.Client{})
+
+	t.Run("gzip compressed payload", func(t *testing.T) {
+		server := newPayloadServer()
+		defer server.server.Close()
+
+		sut := NewDispatcher(server.server.URL, httpClient, 1, 0, true, 0, RetryPolicy{}, nil).(*dispatcher)
+		err := dispatchAndWait(sut, trackEvents(10))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"gzip"}, server.encoding)
+		assert.Equal(t, 10, len(server.trackEventKeys()))
+	})
+
+	t.Run("uncompressed payload", func(t *testing.T) {
+		server := newPayloadServer()
+		defer server.server.Close()
+
+		sut := NewDispatcher(server.server.URL, httpClient, 1, 0, false, 0, RetryPolicy{}, nil).(*dispatcher)
+		err := dispatchAndWait(sut, trackEvents(10))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{""}, server.encoding)
+		assert.Equal(t, 10, len(server.trackEventKeys()))
+	})
+
+	for _, compression := range []bool{false, true} {
+		t.Run(fmt.Sprintf("when payload exceeds max bytes then split (compression=%v)", compression), func(t *testing.T) {
+			server := newPayloadServer()
+			defer server.server.Close()
+
+			events := trackEvents(10)
+			sut := NewDispatcher(server.server.URL, httpClient, 1, 0, compression, 1000, RetryPolicy{}, nil).(*dispatcher)
+			err := dispatchAndWait(sut, events)
+
+			assert.Nil(t, err)
+			assert.True(t, len(server.payloads) > 1)
+			for _, size := range server.sizes {
+				assert.True(t, size <= 1000)
+			}
+			var expected []string
+			for _, e := range events {
+				expected = append(expected, e.(TrackEvent).EventType.Key)
+			}
+			assert.Equal(t, expected, server.trackEventKeys())
+		})
+	}
+
+	t.Run("when single event exceeds max bytes then send it anyway", func(t *testing.T) {
+		server := newPayloadServer()
+		defer server.server.Close()
+
+		sut := NewDispatcher(server.server.URL, httpClient, 1, 0, true, 10, RetryPolicy{}, nil).(*dispatcher)
+		err := dispatchAndWait(sut, trackEvents(3))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(server.payloads))
+	})
+
+	t.Run("dead letter only failed part", func(t *testing.T) {
+		httpClient := &mockStatusHttpClient{statuses: []int{200, 400}}
+		var deadLetters [][]UserEvent
+		events := trackEvents(4)
+		half, _ := json.Marshal(NewPayloadDTO(events[:2]))
+		sut := NewDispatcher("localhost", httpClient, 10, 0, false, len(half), RetryPolicy{}, func(userEvents []UserEvent) {
+			deadLetters = append(deadLetters, userEvents)
+		}).(*dispatcher)
+
+		err := dispatchAndWait(sut, events)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, [][]UserEvent{events[2:]}, deadLetters)
+	})
+}
+
+type mockStatusHttpClient struct {
+	mu       sync.Mutex
+	statuses []int
+	count    int
+}
+
+func (m *mockStatusHttpClient) Execute(req *nethttp.Request) (*nethttp.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := m.statuses[m.count]
+	m.count++
+	if status == 0 {
+		return nil, errors.New("network error")
+	}
+	return &nethttp.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewReader(make([]byte, 0))),
+	}, nil
+}
+
+func (m *mockStatusHttpClient) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.count
+}
+
 type mockHttpClient struct {
 	mu    sync.Mutex
 	req   *nethttp.Request
@@ -179,3 +641,9 @@ func (m *mockHttpClient) Called() bool {
 	defer m.mu.Unlock()
 	return m.req != nil
 }
+
+func dispatchAndWait(d *dispatcher, userEvents []UserEvent) error {
+	errs := make(chan error, 1)
+	d.dispatch(userEvents, func(err error) { errs <- err })
+	return <-errs
+}
@@ -1,6 +1,10 @@
 package event
 
-import "github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+)
 
 type PayloadDTO struct {
 	ExposureEvents     []ExposureEventDTO     `json:"exposureEvents"`
@@ -132,3 +136,53 @@ func NewRemoteConfigEventDTO(event RemoteConfigEvent) RemoteConfigEventDTO {
 		Properties:       event.Properties,
 	}
 }
+
+func (dto ExposureEventDTO) toEvent() ExposureEvent {
+	return ExposureEvent{
+		UserEvent: baseUserEvent{
+			insertID:  dto.InsertID,
+			timestamp: dto.Timestamp,
+			user:      user.HackleUser{Identifiers: dto.Identifiers, Properties: dto.UserProperties},
+		},
+		Experiment: model.Experiment{
+			ID:      dto.ExperimentID,
+			Key:     dto.ExperimentKey,
+			Type:    model.ExperimentType(dto.ExperimentType),
+			Version: dto.ExperimentVersion,
+		},
+		VariationID:    dto.VariationID,
+		VariationKey:   dto.VariationKey,
+		DecisionReason: dto.DecisionReason,
+		Properties:     dto.Properties,
+	}
+}
+
+func (dto TrackEventDTO) toEvent() TrackEvent {
+	return TrackEvent{
+		UserEvent: baseUserEvent{
+			insertID:  dto.InsertID,
+			timestamp: dto.Timestamp,
+			user:      user.HackleUser{Identifiers: dto.Identifiers, Properties: dto.UserProperties},
+		},
+		EventType: model.EventType{ID: dto.EventTypeID, Key: dto.EventTypeKey},
+		Event:     event{key: dto.EventTypeKey, value: dto.Value, properties: dto.Properties},
+	}
+}
+
+func (dto RemoteConfigEventDTO) toEvent() RemoteConfigEvent {
+	return RemoteConfigEvent{
+		UserEvent: baseUserEvent{
+			insertID:  dto.InsertID,
+			timestamp: dto.Timestamp,
+			user:      user.HackleUser{Identifiers: dto.Identifiers, Properties: dto.UserProperties},
+		},
+		Parameter: model.RemoteConfigParameter{
+			ID:   dto.ParameterID,
+			Key:  dto.ParameterKey,
+			Type: types.ValueType(dto.ParameterType),
+		},
+		ValueID:        dto.ValueID,
+		DecisionReason: dto.DecisionReason,
+		Properties:     dto.Properties,
+	}
+}
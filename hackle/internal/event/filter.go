@@ -0,0 +1,133 @@
+package event
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/bucketer"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+)
+
+type Filter interface {
+	Apply(event UserEvent) (UserEvent, bool)
+}
+
+func NewPredicateFilter(predicate func(event UserEvent) bool) Filter {
+	return &predicateFilter{predicate: predicate}
+}
+
+type predicateFilter struct {
+	predicate func(event UserEvent) bool
+}
+
+func (f *predicateFilter) Apply(event UserEvent) (result UserEvent, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in event filter: %v", r)
+			result, ok = event, true
+		}
+	}()
+	if f.predicate(event) {
+		return nil, false
+	}
+	return event, true
+}
+
+func NewTrackSampler(rates map[string]float64, hasher bucketer.Hasher) Filter {
+	return &trackSampler{
+		rates:  rates,
+		hasher: hasher,
+	}
+}
+
+type trackSampler struct {
+	rates  map[string]float64
+	hasher bucketer.Hasher
+}
+
+func (s *trackSampler) Apply(userEvent UserEvent) (UserEvent, bool) {
+	track, ok := userEvent.(TrackEvent)
+	if !ok {
+		return userEvent, true
+	}
+	key := track.Event.Key()
+	rate, ok := s.rates[key]
+	if !ok {
+		return userEvent, true
+	}
+	if s.slot(key, samplingIdentifier(track)) >= int(rate*samplingSlotSize) {
+		return nil, false
+	}
+
+	properties := make(map[string]interface{}, len(track.Event.Properties())+1)
+	for k, v := range track.Event.Properties() {
+		properties[k] = v
+	}
+	properties[samplingRateKey] = rate
+	track.Event = event{key: key, value: track.Event.Value(), properties: properties}
+	return track, true
+}
+
+func (s *trackSampler) slot(key string, identifier string) int {
+	seed := s.hasher.Hash(key, 0)
+	hash := int64(s.hasher.Hash(identifier, seed))
+	if hash < 0 {
+		hash = -hash
+	}
+	return int(hash % samplingSlotSize)
+}
+
+// samplingIdentifier samples the same user consistently, or each event independently if the user has no identifier.
+func samplingIdentifier(event UserEvent) string {
+	identifiers := event.User().Identifiers
+	for _, identifierType := range samplingIdentifierTypes {
+		if id := identifiers[identifierType]; id != "" {
+			return id
+		}
+	}
+	return event.InsertID()
+}
+
+var samplingIdentifierTypes = []string{user.IdentifierTypeID, user.IdentifierTypeUserID, user.IdentifierTypeDeviceID}
+
+const (
+	samplingSlotSize = 10000
+	samplingRateKey  = "$sampling_rate"
+)
+
+func NewFilteringProcessor(processor Processor, filters []Filter) Processor {
+	return &filteringProcessor{
+		Processor: processor,
+		filters:   filters,
+	}
+}
+
+type filteringProcessor struct {
+	Processor
+	filters []Filter
+}
+
+func (p *filteringProcessor) Process(event UserEvent) {
+	if filtered, ok := p.apply(event); ok {
+		p.Processor.Process(filtered)
+	}
+}
+
+func (p *filteringProcessor) ProcessAll(events []UserEvent) int {
+	kept := make([]UserEvent, 0, len(events))
+	for _, event := range events {
+		if filtered, ok := p.apply(event); ok {
+			kept = append(kept, filtered)
+		}
+	}
+	return p.Processor.ProcessAll(kept)
+}
+
+func (p *filteringProcessor) apply(event UserEvent) (UserEvent, bool) {
+	for _, filter := range p.filters {
+		filtered, ok := filter.Apply(event)
+		if !ok {
+			return nil, false
+		}
+		event = filtered
+	}
+	return event, true
+}
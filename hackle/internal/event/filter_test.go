@@ -0,0 +1,142 @@
+package event
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/bucketer"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func track(id string, key string) TrackEvent {
+	return TrackEvent{
+		UserEvent: baseUserEvent{insertID: "insert-" + id, user: user.HackleUser{Identifiers: map[string]string{"$id": id}}},
+		Event:     event{key: key, value: 42.0, properties: map[string]interface{}{"a": "b"}},
+	}
+}
+
+func TestPredicateFilter(t *testing.T) {
+
+	t.Run("drop matched events", func(t *testing.T) {
+		sut := NewPredicateFilter(func(event UserEvent) bool {
+			track, ok := event.(TrackEvent)
+			return ok && track.Event.Key() == "drop"
+		})
+
+		_, ok := sut.Apply(track("a", "drop"))
+		assert.Equal(t, false, ok)
+
+		filtered, ok := sut.Apply(track("a", "keep"))
+		assert.Equal(t, true, ok)
+		assert.Equal(t, track("a", "keep"), filtered)
+	})
+
+	t.Run("when predicate panics then keep event", func(t *testing.T) {
+		sut := NewPredicateFilter(func(event UserEvent) bool {
+			panic("predicate panic")
+		})
+
+		filtered, ok := sut.Apply(track("a", "keep"))
+		assert.Equal(t, true, ok)
+		assert.Equal(t, track("a", "keep"), filtered)
+	})
+}
+
+func TestTrackSampler(t *testing.T) {
+
+	t.Run("sample by rate", func(t *testing.T) {
+		sut := NewTrackSampler(map[string]float64{"page_view": 0.1}, bucketer.NewHasher())
+
+		kept := 0
+		for i := 0; i < 10000; i++ {
+			filtered, ok := sut.Apply(track(strconv.Itoa(i), "page_view"))
+			if ok {
+				kept++
+				assert.Equal(t, 0.1, filtered.(TrackEvent).Event.Properties()["$sampling_rate"])
+				assert.Equal(t, "b", filtered.(TrackEvent).Event.Properties()["a"])
+				assert.Equal(t, 42.0, filtered.(TrackEvent).Event.Value())
+			}
+		}
+		assert.InDelta(t, 1000, kept, 100)
+	})
+
+	t.Run("same user is sampled deterministically", func(t *testing.T) {
+		sut := NewTrackSampler(map[string]float64{"page_view": 0.5}, bucketer.NewHasher())
+
+		for i := 0; i < 100; i++ {
+			_, first := sut.Apply(track(strconv.Itoa(i), "page_view"))
+			_, second := sut.Apply(track(strconv.Itoa(i), "page_view"))
+			assert.Equal(t, first, second)
+		}
+	})
+
+	t.Run("when rate is 0 or 1 then drop or keep all", func(t *testing.T) {
+		sut := NewTrackSampler(map[string]float64{"none": 0, "all": 1}, bucketer.NewHasher())
+
+		for i := 0; i < 100; i++ {
+			_, ok := sut.Apply(track(strconv.Itoa(i), "none"))
+			assert.Equal(t, false, ok)
+			_, ok = sut.Apply(track(strconv.Itoa(i), "all"))
+			assert.Equal(t, true, ok)
+		}
+	})
+
+	t.Run("sample by $id, $userId or $deviceId", func(t *testing.T) {
+		userEvent := func(identifiers map[string]string) UserEvent {
+			return baseUserEvent{insertID: "insert", user: user.HackleUser{Identifiers: identifiers}}
+		}
+
+		assert.Equal(t, "id", samplingIdentifier(userEvent(map[string]string{"$id": "id", "$userId": "user", "$deviceId": "device"})))
+		assert.Equal(t, "user", samplingIdentifier(userEvent(map[string]string{"$userId": "user", "$deviceId": "device"})))
+		assert.Equal(t, "user", samplingIdentifier(userEvent(map[string]string{"$id": "", "$userId": "user"})))
+		assert.Equal(t, "device", samplingIdentifier(userEvent(map[string]string{"$deviceId": "device", "custom": "c"})))
+	})
+
+	t.Run("when user has no identifier then sample each event independently", func(t *testing.T) {
+		sut := NewTrackSampler(map[string]float64{"page_view": 0.1}, bucketer.NewHasher())
+
+		kept := 0
+		for i := 0; i < 10000; i++ {
+			anonymous := TrackEvent{
+				UserEvent: baseUserEvent{insertID: "insert-" + strconv.Itoa(i), user: user.HackleUser{Identifiers: map[string]string{}}},
+				Event:     event{key: "page_view"},
+			}
+			if _, ok := sut.Apply(anonymous); ok {
+				kept++
+			}
+		}
+		assert.InDelta(t, 1000, kept, 100)
+	})
+
+	t.Run("do not sample other events", func(t *testing.T) {
+		sut := NewTrackSampler(map[string]float64{"page_view": 0}, bucketer.NewHasher())
+
+		filtered, ok := sut.Apply(track("a", "purchase"))
+		assert.Equal(t, true, ok)
+		assert.Equal(t, track("a", "purchase"), filtered)
+
+		filtered, ok = sut.Apply(storedEvents()[0])
+		assert.Equal(t, true, ok)
+		assert.Equal(t, storedEvents()[0], filtered)
+	})
+}
+
+func TestFilteringProcessor(t *testing.T) {
+	delegate := &mockProcessor{}
+	sut := NewFilteringProcessor(delegate, []Filter{
+		NewPredicateFilter(func(event UserEvent) bool {
+			return event.InsertID() == "insert-drop"
+		}),
+		NewTrackSampler(map[string]float64{"page_view": 1}, bucketer.NewHasher()),
+	})
+
+	sut.Process(track("drop", "page_view"))
+	sut.Process(track("keep", "page_view"))
+	sut.Process(track("keep", "purchase"))
+	accepted := sut.ProcessAll([]UserEvent{track("drop", "purchase"), track("keep", "page_view")})
+
+	assert.Equal(t, 1, accepted)
+	assert.Equal(t, 3, len(delegate.processed))
+	assert.Equal(t, 1.0, delegate.processed[0].(TrackEvent).Event.Properties()["$sampling_rate"])
+	assert.Nil(t, delegate.processed[1].(TrackEvent).Event.Properties()["$sampling_rate"])
+}
@@ -0,0 +1,107 @@
+package event
+
+import (
+	"context"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"sync"
+	"sync/atomic"
+)
+
+type Listener func(event UserEvent)
+
+func NewListeningProcessor(processor Processor, listeners []Listener, capacity int) Processor {
+	p := &listeningProcessor{
+		Processor: processor,
+		listeners: listeners,
+		queue:     make(chan UserEvent, capacity),
+		done:      make(chan struct{}),
+	}
+	go p.notifyLoop()
+	return p
+}
+
+type listeningProcessor struct {
+	Processor
+	droppedCount int64
+	listeners    []Listener
+	queue        chan UserEvent
+	done         chan struct{}
+	closed       bool
+	mu           sync.RWMutex
+}
+
+// Process notifies the listeners only if the wrapped processor accepted the event.
+func (p *listeningProcessor) Process(event UserEvent) {
+	p.ProcessAll([]UserEvent{event})
+}
+
+// ProcessAll notifies the listeners only if the wrapped processor accepted all events, as it queues all or none of them.
+func (p *listeningProcessor) ProcessAll(events []UserEvent) int {
+	accepted := p.Processor.ProcessAll(events)
+	if accepted == len(events) {
+		for _, event := range events {
+			p.enqueue(event)
+		}
+	}
+	return accepted
+}
+
+func (p *listeningProcessor) enqueue(event UserEvent) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- event:
+	default:
+		dropped := atomic.AddInt64(&p.droppedCount, 1)
+		if dropped == 1 || dropped%1000 == 0 {
+			logger.Warn("Event listener queue is full. %d events not notified.", dropped)
+		}
+	}
+}
+
+func (p *listeningProcessor) notifyLoop() {
+	defer close(p.done)
+	for event := range p.queue {
+		for _, listener := range p.listeners {
+			p.notify(listener, event)
+		}
+	}
+}
+
+func (p *listeningProcessor) notify(listener Listener, event UserEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in event listener: %v", r)
+		}
+	}()
+	listener(event)
+}
+
+func (p *listeningProcessor) Close() {
+	p.Processor.Close()
+	p.stop(context.Background())
+}
+
+func (p *listeningProcessor) CloseWithContext(ctx context.Context) error {
+	err := p.Processor.CloseWithContext(ctx)
+	p.stop(ctx)
+	return err
+}
+
+func (p *listeningProcessor) stop(ctx context.Context) {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		logger.Warn("Event listeners not completed before shutdown: %v", ctx.Err())
+	}
+}
@@ -0,0 +1,168 @@
+package event
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockProcessor struct {
+	processed []UserEvent
+	closed    bool
+	mu        sync.Mutex
+}
+
+func (m *mockProcessor) Process(event UserEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processed = append(m.processed, event)
+}
+
+func (m *mockProcessor) ProcessAll(events []UserEvent) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processed = append(m.processed, events...)
+	return len(events)
+}
+
+func (m *mockProcessor) DroppedCount() int64 {
+	return 0
+}
+
+func (m *mockProcessor) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockProcessor) Start() {}
+
+func (m *mockProcessor) Close() {
+	m.closed = true
+}
+
+func (m *mockProcessor) CloseWithContext(ctx context.Context) error {
+	m.closed = true
+	return nil
+}
+
+func TestListeningProcessor(t *testing.T) {
+
+	t.Run("notify every listener after processing", func(t *testing.T) {
+		delegate := &mockProcessor{}
+		var mu sync.Mutex
+		var first, second []string
+		sut := NewListeningProcessor(delegate, []Listener{
+			func(event UserEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				first = append(first, event.InsertID())
+			},
+			func(event UserEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				second = append(second, event.InsertID())
+			},
+		}, 10)
+
+		sut.Process(baseUserEvent{insertID: "a"})
+		assert.Equal(t, 2, sut.ProcessAll([]UserEvent{baseUserEvent{insertID: "b"}, baseUserEvent{insertID: "c"}}))
+		sut.Close()
+
+		assert.Equal(t, 3, len(delegate.processed))
+		assert.Equal(t, true, delegate.closed)
+		assert.Equal(t, []string{"a", "b", "c"}, first)
+		assert.Equal(t, []string{"a", "b", "c"}, second)
+	})
+
+	t.Run("when queue is full then do not notify rejected events", func(t *testing.T) {
+		var mu sync.Mutex
+		var notified []string
+		delegate := NewProcessor(2, OverflowDropNewest, time.Second, &mockDispatcher{}, 100, &mockScheduler{}, 10*time.Second, NewNoopStore())
+		sut := NewListeningProcessor(delegate, []Listener{
+			func(event UserEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				notified = append(notified, event.InsertID())
+			},
+		}, 10)
+
+		sut.Process(baseUserEvent{insertID: "a"})
+		assert.Equal(t, 0, sut.ProcessAll([]UserEvent{baseUserEvent{insertID: "b"}, baseUserEvent{insertID: "c"}}))
+		sut.Process(baseUserEvent{insertID: "d"})
+		sut.Process(baseUserEvent{insertID: "e"})
+		sut.Close()
+
+		assert.Equal(t, int64(3), delegate.DroppedCount())
+		assert.Equal(t, []string{"a", "d"}, notified)
+	})
+
+	t.Run("when listener panics then continue to notify", func(t *testing.T) {
+		var notified []string
+		sut := NewListeningProcessor(&mockProcessor{}, []Listener{
+			func(event UserEvent) {
+				panic("listener panic")
+			},
+			func(event UserEvent) {
+				notified = append(notified, event.InsertID())
+			},
+		}, 10)
+
+		sut.Process(baseUserEvent{insertID: "a"})
+		sut.Process(baseUserEvent{insertID: "b"})
+		sut.Close()
+
+		assert.Equal(t, []string{"a", "b"}, notified)
+	})
+
+	t.Run("when listener is slow then do not block process", func(t *testing.T) {
+		delegate := &mockProcessor{}
+		release := make(chan struct{})
+		sut := NewListeningProcessor(delegate, []Listener{
+			func(event UserEvent) {
+				<-release
+			},
+		}, 1)
+
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			sut.Process(baseUserEvent{})
+		}
+		assert.True(t, time.Since(start) < 100*time.Millisecond)
+		assert.Equal(t, 10, len(delegate.processed))
+
+		close(release)
+		assert.Nil(t, sut.CloseWithContext(context.Background()))
+	})
+
+	t.Run("when listener does not complete then close with context returns", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		sut := NewListeningProcessor(&mockProcessor{}, []Listener{
+			func(event UserEvent) {
+				<-release
+			},
+		}, 10)
+		sut.Process(baseUserEvent{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.Nil(t, sut.CloseWithContext(ctx))
+	})
+
+	t.Run("after closed then do not notify", func(t *testing.T) {
+		count := 0
+		delegate := &mockProcessor{}
+		sut := NewListeningProcessor(delegate, []Listener{
+			func(event UserEvent) {
+				count++
+			},
+		}, 10)
+		sut.Close()
+		sut.Process(baseUserEvent{})
+		sut.Close()
+
+		assert.Equal(t, 0, count)
+		assert.Equal(t, 1, len(delegate.processed))
+	})
+}
@@ -1,57 +1,244 @@
 package event
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Processor interface {
 	Process(event UserEvent)
+	ProcessAll(events []UserEvent) (accepted int)
+	DroppedCount() int64
+	Flush(ctx context.Context) error
 	Start()
 	Close()
+	CloseWithContext(ctx context.Context) error
 }
 
+type AbandonedError struct {
+	Count int
+	Err   error
+}
+
+func (e *AbandonedError) Error() string {
+	return fmt.Sprintf("%d events abandoned: %v", e.Count, e.Err)
+}
+
+func (e *AbandonedError) Unwrap() error {
+	return e.Err
+}
+
+type OverflowPolicy int
+
+const (
+	OverflowDropNewest OverflowPolicy = iota
+	OverflowDropOldest
+	OverflowBlock
+)
+
 func NewProcessor(
 	capacity int,
+	overflowPolicy OverflowPolicy,
+	blockTimeout time.Duration,
 	dispatcher Dispatcher,
 	dispatchSize int,
 	flushScheduler schedule.Scheduler,
 	flushInterval time.Duration,
+	store Store,
 ) Processor {
 	return &processor{
 		queue:          make(chan message, capacity),
+		control:        make(chan message, 1),
+		overflowPolicy: overflowPolicy,
+		blockTimeout:   blockTimeout,
 		dispatcher:     dispatcher,
+		store:          store,
 		dispatchSize:   dispatchSize,
 		flushScheduler: flushScheduler,
 		flushInterval:  flushInterval,
 		consumingWait:  &sync.WaitGroup{},
 		flushingJob:    nil,
 		isStarted:      false,
+		pending:        make(map[*pendingBatch]struct{}),
+		segmentRefs:    make(map[string]int),
+		sealedSegments: make(map[string]struct{}),
 	}
 }
 
 type processor struct {
+	droppedCount   int64
 	queue          chan message
+	control        chan message
+	overflowPolicy OverflowPolicy
+	blockTimeout   time.Duration
 	dispatcher     Dispatcher
+	store          Store
 	dispatchSize   int
 	flushScheduler schedule.Scheduler
 	flushInterval  time.Duration
 	consumingWait  *sync.WaitGroup
 	flushingJob    schedule.Job
 	isStarted      bool
+	isClosed       bool
 	mu             sync.Mutex
+	pending        map[*pendingBatch]struct{}
+	pendingMu      sync.Mutex
+	offerMu        sync.Mutex
+	space          chan struct{}
+	segmentRefs    map[string]int
+	sealedSegments map[string]struct{}
+	segmentMu      sync.Mutex
+}
+
+type pendingBatch struct {
+	size int
+	done chan struct{}
+	err  error
 }
 
 func (p *processor) Process(event UserEvent) {
-	select {
-	case p.queue <- eventMessage{event}:
+	p.offer([]UserEvent{event})
+}
+
+func (p *processor) ProcessAll(events []UserEvent) int {
+	if len(events) == 0 || !p.offer(events) {
+		return 0
+	}
+	return len(events)
+}
+
+// offer puts either all events in the queue or none of them.
+// Under OverflowDropOldest, queued events are evicted and counted as dropped to make room for the new events.
+func (p *processor) offer(events []UserEvent) bool {
+	if len(events) > cap(p.queue) {
+		p.drop(len(events))
+		return false
+	}
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		// only the consumer takes from the queue outside offerMu, so the free space checked here can only grow
+		p.offerMu.Lock()
+		if cap(p.queue)-len(p.queue) >= len(events) {
+			for _, event := range events {
+				p.queue <- eventMessage{event: event, segment: p.persist(event)}
+			}
+			p.offerMu.Unlock()
+			return true
+		}
+
+		switch p.overflowPolicy {
+		case OverflowDropOldest:
+			var evicted message
+			select {
+			case evicted = <-p.queue:
+			default:
+			}
+			p.offerMu.Unlock()
+			if m, ok := evicted.(eventMessage); ok {
+				p.release([]string{m.segment})
+				p.drop(1)
+			}
+		case OverflowBlock:
+			if p.space == nil {
+				p.space = make(chan struct{})
+			}
+			space := p.space
+			p.offerMu.Unlock()
+			if timer == nil {
+				timer = time.NewTimer(p.blockTimeout)
+			}
+			select {
+			case <-space:
+			case <-timer.C:
+				p.drop(len(events))
+				return false
+			}
+		default:
+			p.offerMu.Unlock()
+			p.drop(len(events))
+			return false
+		}
+	}
+}
+
+// persist stores the event before it is queued, so that queued events survive a crash.
+func (p *processor) persist(event UserEvent) string {
+	p.segmentMu.Lock()
+	defer p.segmentMu.Unlock()
+	segment, ok := p.store.Append(event)
+	if !ok {
+		return ""
+	}
+	p.segmentRefs[segment]++
+	return segment
+}
+
+// seal closes the current segment. A sealed segment is removed once none of its events is queued or undelivered.
+func (p *processor) seal() {
+	p.segmentMu.Lock()
+	defer p.segmentMu.Unlock()
+	segment, ok := p.store.Seal()
+	if !ok {
 		return
-	default:
-		logger.Info("Event not processed. Exceed event capacity.")
 	}
+	if p.segmentRefs[segment] == 0 {
+		delete(p.segmentRefs, segment)
+		p.store.Remove(segment)
+		return
+	}
+	p.sealedSegments[segment] = struct{}{}
+}
+
+func (p *processor) release(segments []string) {
+	p.segmentMu.Lock()
+	defer p.segmentMu.Unlock()
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		p.segmentRefs[segment]--
+		if p.segmentRefs[segment] > 0 {
+			continue
+		}
+		delete(p.segmentRefs, segment)
+		if _, ok := p.sealedSegments[segment]; ok {
+			delete(p.sealedSegments, segment)
+			p.store.Remove(segment)
+		}
+	}
+}
+
+// taken wakes up offers waiting for space in the queue.
+func (p *processor) taken() {
+	p.offerMu.Lock()
+	defer p.offerMu.Unlock()
+	if p.space != nil {
+		close(p.space)
+		p.space = nil
+	}
+}
+
+func (p *processor) drop(count int) {
+	dropped := atomic.AddInt64(&p.droppedCount, int64(count))
+	if dropped == int64(count) || dropped/1000 != (dropped-int64(count))/1000 {
+		logger.Warn("Event dropped. Exceed event capacity. %d events dropped so far.", dropped)
+	}
+}
+
+func (p *processor) DroppedCount() int64 {
+	return atomic.LoadInt64(&p.droppedCount)
 }
 
 func (p *processor) Start() {
@@ -62,68 +249,276 @@ func (p *processor) Start() {
 		return
 	}
 
+	p.recover()
+
 	p.consumingWait.Add(1)
 	go p.consuming()
 
 	p.flushingJob = p.flushScheduler.SchedulePeriodically(p.flushInterval, func() {
-		p.queue <- flushMessage{}
+		select {
+		case p.control <- flushMessage{}:
+		default:
+		}
 	})
 
 	p.isStarted = true
 	logger.Info(fmt.Sprintf("EventProcessor started. Flush events every %s", p.flushInterval))
 }
 
-func (p *processor) Close() {
+func (p *processor) Flush(ctx context.Context) error {
+	reply := make(chan []*pendingBatch, 1)
+	err := p.requestFlush(ctx, reply)
+	if err != nil {
+		return err
+	}
+
+	var batches []*pendingBatch
+	select {
+	case batches = <-reply:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush events: %w", ctx.Err())
+	}
+
+	var dispatchErr error
+	for _, batch := range batches {
+		select {
+		case <-batch.done:
+			if batch.err != nil && dispatchErr == nil {
+				dispatchErr = batch.err
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("failed to flush events: %w", ctx.Err())
+		}
+	}
+	if dispatchErr != nil {
+		return fmt.Errorf("failed to flush events: %w", dispatchErr)
+	}
+	return nil
+}
+
+func (p *processor) requestFlush(ctx context.Context, reply chan []*pendingBatch) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if !p.isStarted {
-		return
+	if !p.isStarted || p.isClosed {
+		return errors.New("failed to flush events: event processor is not running")
 	}
+	select {
+	case p.control <- flushMessage{reply: reply}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush events: %w", ctx.Err())
+	}
+}
+
+func (p *processor) Close() {
+	_ = p.CloseWithContext(context.Background())
+}
+
+func (p *processor) CloseWithContext(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.isStarted || p.isClosed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.isClosed = true
+	p.mu.Unlock()
 	logger.Info("EventProcessor shutting down.")
 
 	if p.flushingJob != nil {
 		p.flushingJob.Cancel()
 	}
 
-	p.queue <- shutdownMessage{}
-	p.consumingWait.Wait()
+	consumed := make(chan struct{})
+	go func() {
+		p.control <- shutdownMessage{}
+		p.consumingWait.Wait()
+		close(consumed)
+	}()
+
+	select {
+	case <-consumed:
+	case <-ctx.Done():
+		return p.abandon(ctx, len(p.queue))
+	}
+
+	dispatcherClosed := make(chan struct{})
+	go func() {
+		p.dispatcher.Close()
+		close(dispatcherClosed)
+	}()
+
+	select {
+	case <-dispatcherClosed:
+		p.store.Close()
+		logger.Info("EventProcessor terminated.")
+		return nil
+	case <-ctx.Done():
+		return p.abandon(ctx, 0)
+	}
+}
 
-	p.dispatcher.Close()
-	logger.Info("EventProcessor terminated.")
+// abandon reports the events still queued or being dispatched when the shutdown deadline is exceeded.
+func (p *processor) abandon(ctx context.Context, queued int) error {
+	p.store.Close()
+	abandoned := p.pendingCount() + queued
+	logger.Warn("EventProcessor shutdown deadline exceeded. %d events abandoned.", abandoned)
+	return &AbandonedError{Count: abandoned, Err: ctx.Err()}
 }
 
 func (p *processor) consuming() {
 	defer p.consumingWait.Done()
-	var events []UserEvent
+	var batch []eventMessage
 	for {
 		select {
-		case msg := <-p.queue:
+		case msg := <-p.control:
 			switch m := msg.(type) {
-			case eventMessage:
-				events = append(events, m.event)
-				if len(events) >= p.dispatchSize {
-					p.dispatch(events)
-					events = nil
-				}
 			case flushMessage:
-				p.dispatch(events)
-				events = nil
+				if m.reply != nil {
+					batch = p.drain(batch)
+				}
+				p.dispatch(batch)
+				batch = nil
+				if m.reply != nil {
+					m.reply <- p.pendingBatches()
+				}
 			case shutdownMessage:
-				p.dispatch(events)
+				batch = p.drain(batch)
+				p.dispatch(batch)
 				return
 			}
+		case msg := <-p.queue:
+			batch = p.consume(batch, msg)
 		}
 	}
 }
 
-func (p *processor) dispatch(events []UserEvent) {
-	if len(events) == 0 {
+func (p *processor) consume(batch []eventMessage, msg message) []eventMessage {
+	p.taken()
+	m, ok := msg.(eventMessage)
+	if !ok {
+		return batch
+	}
+	batch = append(batch, m)
+	if len(batch) >= p.dispatchSize {
+		p.dispatch(batch)
+		return nil
+	}
+	return batch
+}
+
+func (p *processor) drain(batch []eventMessage) []eventMessage {
+	for {
+		select {
+		case msg := <-p.queue:
+			batch = p.consume(batch, msg)
+		default:
+			return batch
+		}
+	}
+}
+
+func (p *processor) dispatch(batch []eventMessage) {
+	if len(batch) == 0 {
 		return
 	}
-	p.dispatcher.Dispatch(events)
+	p.seal()
+	events := make([]UserEvent, len(batch))
+	segments := make([]string, len(batch))
+	for i, m := range batch {
+		events[i] = m.event
+		segments[i] = m.segment
+	}
+	p.dispatcher.Dispatch(events, p.track(len(events), func() {
+		p.release(segments)
+	}))
+}
+
+func (p *processor) recover() {
+	for _, batch := range p.store.Recover() {
+		segment := batch.Segment
+		p.dispatcher.Dispatch(batch.Events, p.track(len(batch.Events), func() {
+			p.store.Remove(segment)
+		}))
+	}
+}
+
+func (p *processor) track(size int, delivered func()) func(err error) {
+	batch := &pendingBatch{size: size, done: make(chan struct{})}
+	p.pendingMu.Lock()
+	p.pending[batch] = struct{}{}
+	p.pendingMu.Unlock()
+
+	return func(err error) {
+		if isDelivered(err) {
+			delivered()
+		}
+		p.pendingMu.Lock()
+		delete(p.pending, batch)
+		p.pendingMu.Unlock()
+		batch.err = err
+		close(batch.done)
+	}
+}
+
+// isDelivered reports whether the events no longer need to be kept in the store.
+// Undelivered events are replayed on the next start unless handed to the DeadLetterHandler.
+func isDelivered(err error) bool {
+	var deadLettered *DeadLetteredError
+	return err == nil || errors.As(err, &deadLettered)
+}
+
+func (p *processor) pendingBatches() []*pendingBatch {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	batches := make([]*pendingBatch, 0, len(p.pending))
+	for batch := range p.pending {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func (p *processor) pendingCount() int {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	count := 0
+	for batch := range p.pending {
+		count += batch.size
+	}
+	return count
 }
 
 type message interface{}
-type eventMessage struct{ event UserEvent }
-type flushMessage struct{}
+type eventMessage struct {
+	event   UserEvent
+	segment string
+}
+type flushMessage struct{ reply chan []*pendingBatch }
 type shutdownMessage struct{}
+
+func NewNoopProcessor() Processor {
+	return &noopProcessor{}
+}
+
+type noopProcessor struct{}
+
+func (p *noopProcessor) Process(event UserEvent) {}
+
+func (p *noopProcessor) ProcessAll(events []UserEvent) int {
+	return len(events)
+}
+
+func (p *noopProcessor) DroppedCount() int64 {
+	return 0
+}
+
+func (p *noopProcessor) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (p *noopProcessor) Start() {}
+
+func (p *noopProcessor) Close() {}
+
+func (p *noopProcessor) CloseWithContext(ctx context.Context) error {
+	return nil
+}
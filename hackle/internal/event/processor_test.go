@@ -1,9 +1,12 @@
 package event
 
 import (
+	"context"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -23,18 +26,23 @@ func eventProcessor(capacity int, dispatchSize int, flushInterval time.Duration)
 	}
 	return &processor{
 		queue:          f.queue,
+		control:        make(chan message, 1),
 		dispatcher:     f.dispatcher,
+		store:          NewNoopStore(),
 		dispatchSize:   dispatchSize,
 		flushScheduler: schedule.NewTickerScheduler(),
 		flushInterval:  flushInterval,
 		consumingWait:  f.consumingWait,
 		flushingJob:    nil,
 		isStarted:      false,
+		pending:        make(map[*pendingBatch]struct{}),
+		segmentRefs:    make(map[string]int),
+		sealedSegments: make(map[string]struct{}),
 	}, f
 }
 
 func TestNewProcessor(t *testing.T) {
-	p := NewProcessor(42, &mockDispatcher{}, 320, &mockScheduler{}, 100*time.Millisecond)
+	p := NewProcessor(42, OverflowDropNewest, time.Second, &mockDispatcher{}, 320, &mockScheduler{}, 100*time.Millisecond, NewNoopStore())
 	assert.IsType(t, &processor{}, p)
 }
 
@@ -65,6 +73,51 @@ func TestProcessor_Process(t *testing.T) {
 
 		sut.Process(baseUserEvent{})
 		assert.Equal(t, 10, len(f.queue))
+		assert.Equal(t, int64(1), sut.DroppedCount())
+	})
+
+	t.Run("when queue is full and drop oldest policy then replace oldest event", func(t *testing.T) {
+		sut, f := eventProcessor(3, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowDropOldest
+
+		for i := 0; i < 5; i++ {
+			sut.Process(baseUserEvent{insertID: strconv.Itoa(i)})
+		}
+
+		assert.Equal(t, int64(2), sut.DroppedCount())
+		assert.Equal(t, "2", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "3", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "4", (<-f.queue).(eventMessage).event.InsertID())
+	})
+
+	t.Run("when queue is full and block policy then wait for space", func(t *testing.T) {
+		sut, f := eventProcessor(1, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowBlock
+		sut.blockTimeout = time.Second
+
+		sut.Process(baseUserEvent{insertID: "1"})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			sut.consume(nil, <-f.queue)
+		}()
+		sut.Process(baseUserEvent{insertID: "2"})
+
+		assert.Equal(t, int64(0), sut.DroppedCount())
+		assert.Equal(t, "2", (<-f.queue).(eventMessage).event.InsertID())
+	})
+
+	t.Run("when queue is full and block policy then drop after timeout", func(t *testing.T) {
+		sut, f := eventProcessor(1, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowBlock
+		sut.blockTimeout = 50 * time.Millisecond
+
+		sut.Process(baseUserEvent{insertID: "1"})
+		start := time.Now()
+		sut.Process(baseUserEvent{insertID: "2"})
+
+		assert.True(t, time.Since(start) >= 50*time.Millisecond)
+		assert.Equal(t, int64(1), sut.DroppedCount())
+		assert.Equal(t, 1, len(f.queue))
 	})
 
 	t.Run("when dispatch size not reached then do not dispatch", func(t *testing.T) {
@@ -145,6 +198,121 @@ func TestProcessor_Process(t *testing.T) {
 	})
 }
 
+func TestProcessor_ProcessAll(t *testing.T) {
+
+	t.Run("put all events in the queue in order", func(t *testing.T) {
+		sut, f := eventProcessor(10, 100, 10*time.Second)
+
+		accepted := sut.ProcessAll([]UserEvent{baseUserEvent{insertID: "1"}, baseUserEvent{insertID: "2"}})
+
+		assert.Equal(t, 2, accepted)
+		assert.Equal(t, "1", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "2", (<-f.queue).(eventMessage).event.InsertID())
+	})
+
+	t.Run("when queue has no space for all events then drop all events", func(t *testing.T) {
+		sut, f := eventProcessor(3, 100, 10*time.Second)
+		sut.Process(baseUserEvent{insertID: "0"})
+
+		accepted := sut.ProcessAll([]UserEvent{baseUserEvent{}, baseUserEvent{}, baseUserEvent{}})
+
+		assert.Equal(t, 0, accepted)
+		assert.Equal(t, int64(3), sut.DroppedCount())
+		assert.Equal(t, 1, len(f.queue))
+	})
+
+	t.Run("when events exceed capacity then drop all events", func(t *testing.T) {
+		for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowBlock} {
+			sut, f := eventProcessor(3, 100, 10*time.Second)
+			sut.overflowPolicy = policy
+			sut.blockTimeout = time.Second
+			sut.Process(baseUserEvent{insertID: "0"})
+
+			start := time.Now()
+			accepted := sut.ProcessAll([]UserEvent{baseUserEvent{}, baseUserEvent{}, baseUserEvent{}, baseUserEvent{}})
+
+			assert.True(t, time.Since(start) < 100*time.Millisecond)
+			assert.Equal(t, 0, accepted)
+			assert.Equal(t, int64(4), sut.DroppedCount())
+			assert.Equal(t, "0", (<-f.queue).(eventMessage).event.InsertID())
+		}
+	})
+
+	t.Run("when drop oldest policy then evict queued events as dropped", func(t *testing.T) {
+		sut, f := eventProcessor(3, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowDropOldest
+		sut.Process(baseUserEvent{insertID: "0"})
+		sut.Process(baseUserEvent{insertID: "1"})
+
+		accepted := sut.ProcessAll([]UserEvent{baseUserEvent{insertID: "2"}, baseUserEvent{insertID: "3"}, baseUserEvent{insertID: "4"}})
+
+		assert.Equal(t, 3, accepted)
+		assert.Equal(t, int64(2), sut.DroppedCount())
+		assert.Equal(t, "2", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "3", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "4", (<-f.queue).(eventMessage).event.InsertID())
+	})
+
+	t.Run("when block policy then wait for space for all events", func(t *testing.T) {
+		sut, f := eventProcessor(2, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowBlock
+		sut.blockTimeout = time.Second
+		sut.Process(baseUserEvent{insertID: "0"})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			sut.consume(nil, <-f.queue)
+		}()
+
+		accepted := sut.ProcessAll([]UserEvent{baseUserEvent{insertID: "1"}, baseUserEvent{insertID: "2"}})
+
+		assert.Equal(t, 2, accepted)
+		assert.Equal(t, int64(0), sut.DroppedCount())
+		assert.Equal(t, "1", (<-f.queue).(eventMessage).event.InsertID())
+		assert.Equal(t, "2", (<-f.queue).(eventMessage).event.InsertID())
+	})
+
+	t.Run("when block policy and no space until timeout then drop all events", func(t *testing.T) {
+		sut, f := eventProcessor(2, 100, 10*time.Second)
+		sut.overflowPolicy = OverflowBlock
+		sut.blockTimeout = 50 * time.Millisecond
+		sut.Process(baseUserEvent{insertID: "0"})
+
+		start := time.Now()
+		accepted := sut.ProcessAll([]UserEvent{baseUserEvent{}, baseUserEvent{}})
+
+		assert.True(t, time.Since(start) >= 50*time.Millisecond)
+		assert.Equal(t, 0, accepted)
+		assert.Equal(t, int64(2), sut.DroppedCount())
+		assert.Equal(t, 1, len(f.queue))
+	})
+
+	t.Run("concurrent batches are not interleaved", func(t *testing.T) {
+		sut, f := eventProcessor(1000, 100, 10*time.Second)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			batch := strconv.Itoa(i)
+			go func() {
+				defer wg.Done()
+				events := make([]UserEvent, 100)
+				for j := range events {
+					events[j] = baseUserEvent{insertID: batch}
+				}
+				sut.ProcessAll(events)
+			}()
+		}
+		wg.Wait()
+
+		for i := 0; i < 10; i++ {
+			batch := (<-f.queue).(eventMessage).event.InsertID()
+			for j := 1; j < 100; j++ {
+				assert.Equal(t, batch, (<-f.queue).(eventMessage).event.InsertID())
+			}
+		}
+	})
+}
+
 func TestProcessor_Start(t *testing.T) {
 	t.Run("start once", func(t *testing.T) {
 		scheduler := &mockScheduler{}
@@ -201,6 +369,28 @@ func TestProcessor_Close(t *testing.T) {
 		assert.Equal(t, true, f.dispatcher.closed)
 	})
 
+	t.Run("when queue is full then shutdown is not starved", func(t *testing.T) {
+		sut, f := eventProcessor(10, 1000, 10*time.Second)
+		for i := 0; i < 10; i++ {
+			sut.Process(baseUserEvent{})
+		}
+		sut.Start()
+
+		done := make(chan struct{})
+		go func() {
+			sut.Close()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "expected closed")
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 10, f.dispatcher.EventCount())
+	})
+
 	t.Run("not started", func(t *testing.T) {
 		sut, f := eventProcessor(16*10000, 10, 10*time.Second)
 		sut.Close()
@@ -208,7 +398,152 @@ func TestProcessor_Close(t *testing.T) {
 	})
 }
 
+func TestProcessor_Flush(t *testing.T) {
+
+	t.Run("dispatch buffered events and wait", func(t *testing.T) {
+		sut, f := eventProcessor(100, 100, 10*time.Second)
+		f.dispatcher.delay = 50 * time.Millisecond
+		sut.Start()
+		defer sut.Close()
+
+		sut.Process(baseUserEvent{insertID: "1"})
+		sut.Process(baseUserEvent{insertID: "2"})
+		err := sut.Flush(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, f.dispatcher.DispatchCount())
+		assert.Equal(t, 2, f.dispatcher.EventCount())
+	})
+
+	t.Run("wait batches already in flight", func(t *testing.T) {
+		sut, f := eventProcessor(100, 1, 10*time.Second)
+		f.dispatcher.delay = 50 * time.Millisecond
+		sut.Start()
+		defer sut.Close()
+
+		sut.Process(baseUserEvent{insertID: "1"})
+		sut.Process(baseUserEvent{insertID: "2"})
+		err := sut.Flush(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, f.dispatcher.DispatchCount())
+	})
+
+	t.Run("when dispatch failed then return error", func(t *testing.T) {
+		sut, f := eventProcessor(100, 100, 10*time.Second)
+		f.dispatcher.err = errors.New("dispatch fail")
+		sut.Start()
+		defer sut.Close()
+
+		sut.Process(baseUserEvent{})
+		err := sut.Flush(context.Background())
+
+		assert.Equal(t, "failed to flush events: dispatch fail", err.Error())
+	})
+
+	t.Run("when context done then return error", func(t *testing.T) {
+		sut, f := eventProcessor(100, 100, 10*time.Second)
+		f.dispatcher.delay = time.Second
+		sut.Start()
+
+		sut.Process(baseUserEvent{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := sut.Flush(ctx)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when not started then return error", func(t *testing.T) {
+		sut, _ := eventProcessor(100, 100, 10*time.Second)
+		assert.NotNil(t, sut.Flush(context.Background()))
+	})
+
+	t.Run("when closed then return error", func(t *testing.T) {
+		sut, _ := eventProcessor(100, 100, 10*time.Second)
+		sut.Start()
+		sut.Close()
+		assert.NotNil(t, sut.Flush(context.Background()))
+	})
+}
+
+func TestProcessor_CloseWithContext(t *testing.T) {
+
+	t.Run("when dispatched before deadline then return nil", func(t *testing.T) {
+		sut, f := eventProcessor(100, 100, 10*time.Second)
+		sut.Start()
+
+		sut.Process(baseUserEvent{})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.Nil(t, sut.CloseWithContext(ctx))
+		assert.Equal(t, true, f.dispatcher.closed)
+	})
+
+	t.Run("when deadline exceeded then report abandoned events", func(t *testing.T) {
+		sut, f := eventProcessor(100, 2, 10*time.Second)
+		f.dispatcher.delay = time.Second
+		f.dispatcher.block = true
+		sut.Start()
+
+		for i := 0; i < 5; i++ {
+			sut.Process(baseUserEvent{})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := sut.CloseWithContext(ctx)
+
+		var abandonedErr *AbandonedError
+		assert.True(t, errors.As(err, &abandonedErr))
+		assert.Equal(t, 5, abandonedErr.Count)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, "5 events abandoned: context deadline exceeded", err.Error())
+	})
+
+	t.Run("when deadline exceeded while consuming then report queued events as abandoned", func(t *testing.T) {
+		sut, _ := eventProcessor(100, 2, 10*time.Second)
+		sut.store = &slowStore{Store: NewNoopStore(), delay: time.Second}
+		sut.Start()
+
+		for i := 0; i < 6; i++ {
+			sut.Process(baseUserEvent{})
+		}
+		time.Sleep(20 * time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := sut.CloseWithContext(ctx)
+
+		assert.True(t, time.Since(start) < 500*time.Millisecond)
+		var abandonedErr *AbandonedError
+		assert.True(t, errors.As(err, &abandonedErr))
+		assert.Equal(t, 4, abandonedErr.Count)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("close once", func(t *testing.T) {
+		sut, _ := eventProcessor(100, 100, 10*time.Second)
+		sut.Start()
+		assert.Nil(t, sut.CloseWithContext(context.Background()))
+		assert.Nil(t, sut.CloseWithContext(context.Background()))
+	})
+}
+
+type slowStore struct {
+	Store
+	delay time.Duration
+}
+
+func (s *slowStore) Seal() (string, bool) {
+	time.Sleep(s.delay)
+	return s.Store.Seal()
+}
+
 type mockDispatcher struct {
+	block         bool
+	delay         time.Duration
+	err           error
 	dispatched    []UserEvent
 	dispatchCount int
 	eventCount    int
@@ -217,19 +552,40 @@ type mockDispatcher struct {
 	wg            sync.WaitGroup
 }
 
-func (m *mockDispatcher) Dispatch(userEvents []UserEvent) {
+func (m *mockDispatcher) Dispatch(userEvents []UserEvent, done func(err error)) {
 	go func() {
+		time.Sleep(m.delay)
 		m.mu.Lock()
-		defer m.mu.Unlock()
 		for _, userEvent := range userEvents {
 			m.dispatched = append(m.dispatched, userEvent)
 		}
 		m.dispatchCount++
 		m.eventCount = m.eventCount + len(userEvents)
+		m.mu.Unlock()
+		if done != nil {
+			done(m.err)
+		}
 	}()
 }
 
+func (m *mockDispatcher) InFlightCount() int {
+	return 0
+}
+
+func (m *mockDispatcher) QueuedCount() int {
+	return 0
+}
+
+func (m *mockDispatcher) DroppedCount() int64 {
+	return 0
+}
+
 func (m *mockDispatcher) Close() {
+	if m.block {
+		time.Sleep(m.delay)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.closed = true
 }
 
@@ -261,6 +617,10 @@ func (m *mockScheduler) SchedulePeriodically(period time.Duration, task func())
 	return job
 }
 
+func (m *mockScheduler) SchedulePeriodicallyWithDelay(initialDelay time.Duration, period time.Duration, task func()) schedule.Job {
+	return m.SchedulePeriodically(period, task)
+}
+
 func (m *mockScheduler) jobCount() int {
 	return len(m.jobs)
 }
@@ -272,3 +632,15 @@ type mockJob struct {
 func (m *mockJob) Cancel() {
 	m.canceled = true
 }
+
+func TestNoopProcessor(t *testing.T) {
+	p := NewNoopProcessor()
+	p.Start()
+	p.Process(baseUserEvent{})
+	assert.Equal(t, 2, p.ProcessAll([]UserEvent{baseUserEvent{}, baseUserEvent{}}))
+	assert.Nil(t, p.Flush(context.Background()))
+	p.Close()
+	assert.Nil(t, p.CloseWithContext(context.Background()))
+	assert.IsType(t, &noopProcessor{}, p)
+	assert.Equal(t, int64(0), p.DroppedCount())
+}
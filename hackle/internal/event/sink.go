@@ -0,0 +1,185 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"io"
+	"os"
+	"sync"
+)
+
+type Sink interface {
+	Send(userEvents []UserEvent) error
+	Close() error
+}
+
+func NewWriterSink(writer io.Writer) Sink {
+	return &writerSink{writer: writer}
+}
+
+type writerSink struct {
+	writer io.Writer
+	mu     sync.Mutex
+}
+
+func (s *writerSink) Send(userEvents []UserEvent) error {
+	var buf bytes.Buffer
+	for _, userEvent := range userEvents {
+		record, err := newRecordDTO(userEvent)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.writer.Write(buf.Bytes())
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
+
+func NewFileSink(filename string) (Sink, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{writerSink: writerSink{writer: file}, file: file}, nil
+}
+
+type fileSink struct {
+	writerSink
+	file *os.File
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// NewSinkDispatcher sends batches to the sink one at a time, keeping at most maxQueued batches waiting.
+func NewSinkDispatcher(sink Sink, maxQueued int) Dispatcher {
+	d := &sinkDispatcher{sink: sink}
+	d.queue = newDispatchQueue(1, maxQueued, sendNow(d.send), d.reject)
+	return d
+}
+
+type sinkDispatcher struct {
+	sink  Sink
+	queue *dispatchQueue
+}
+
+func (d *sinkDispatcher) Dispatch(userEvents []UserEvent, done func(err error)) {
+	d.queue.offer(userEvents, done)
+}
+
+func (d *sinkDispatcher) reject(userEvents []UserEvent) error {
+	err := errors.New("event sink queue is full")
+	logger.Error("Failed to send events to sink: %v", err)
+	return err
+}
+
+func (d *sinkDispatcher) send(userEvents []UserEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unexpected panic in event sink: %v", r)
+		}
+		if err != nil {
+			logger.Error("Failed to send events to sink: %v", err)
+		}
+	}()
+	return d.sink.Send(userEvents)
+}
+
+func (d *sinkDispatcher) InFlightCount() int {
+	return d.queue.inFlightCount()
+}
+
+func (d *sinkDispatcher) QueuedCount() int {
+	return d.queue.queuedCount()
+}
+
+func (d *sinkDispatcher) DroppedCount() int64 {
+	return d.queue.droppedCount()
+}
+
+func (d *sinkDispatcher) Close() {
+	d.queue.wait()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in event sink: %v", r)
+		}
+	}()
+	if err := d.sink.Close(); err != nil {
+		logger.Warn("failed to close event sink: %v", err)
+	}
+}
+
+// NewFanOutDispatcher dispatches every batch to the primary dispatcher and mirrors it to the others.
+// A batch is done when all dispatchers completed, but only the error of the primary dispatcher is reported.
+func NewFanOutDispatcher(primary Dispatcher, mirrors ...Dispatcher) Dispatcher {
+	return &fanOutDispatcher{dispatchers: append([]Dispatcher{primary}, mirrors...)}
+}
+
+type fanOutDispatcher struct {
+	dispatchers []Dispatcher
+}
+
+func (d *fanOutDispatcher) Dispatch(userEvents []UserEvent, done func(err error)) {
+	var mu sync.Mutex
+	remaining := len(d.dispatchers)
+	var primaryErr error
+	for i, dispatcher := range d.dispatchers {
+		primary := i == 0
+		dispatcher.Dispatch(userEvents, func(err error) {
+			mu.Lock()
+			remaining--
+			if primary {
+				primaryErr = err
+			}
+			completed := remaining == 0
+			mu.Unlock()
+			if completed && done != nil {
+				done(primaryErr)
+			}
+		})
+	}
+}
+
+func (d *fanOutDispatcher) InFlightCount() int {
+	count := 0
+	for _, dispatcher := range d.dispatchers {
+		count += dispatcher.InFlightCount()
+	}
+	return count
+}
+
+func (d *fanOutDispatcher) QueuedCount() int {
+	count := 0
+	for _, dispatcher := range d.dispatchers {
+		count += dispatcher.QueuedCount()
+	}
+	return count
+}
+
+func (d *fanOutDispatcher) DroppedCount() int64 {
+	return d.dispatchers[0].DroppedCount()
+}
+
+func (d *fanOutDispatcher) Close() {
+	for _, dispatcher := range d.dispatchers {
+		dispatcher.Close()
+	}
+}
@@ -0,0 +1,223 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockSink struct {
+	err      error
+	panics   bool
+	block    chan struct{}
+	received [][]UserEvent
+	closed   bool
+	mu       sync.Mutex
+}
+
+func (m *mockSink) Send(userEvents []UserEvent) error {
+	if m.panics {
+		panic("sink panic")
+	}
+	if m.block != nil {
+		<-m.block
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, userEvents)
+	return m.err
+}
+
+func (m *mockSink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockSink) ReceivedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.received)
+}
+
+func decodeLines(t *testing.T, data []byte) []UserEvent {
+	var events []UserEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record recordDTO
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+		switch {
+		case record.Exposure != nil:
+			events = append(events, record.Exposure.toEvent())
+		case record.Track != nil:
+			events = append(events, record.Track.toEvent())
+		case record.RemoteConfig != nil:
+			events = append(events, record.RemoteConfig.toEvent())
+		}
+	}
+	return events
+}
+
+func TestWriterSink(t *testing.T) {
+
+	t.Run("write each event as a json line", func(t *testing.T) {
+		var buf bytes.Buffer
+		sut := NewWriterSink(&buf)
+
+		events := storedEvents()
+		assert.Nil(t, sut.Send(events))
+		assert.Nil(t, sut.Send(events[:1]))
+		assert.Nil(t, sut.Close())
+
+		assert.Equal(t, 4, strings.Count(buf.String(), "\n"))
+		assert.Equal(t, NewPayloadDTO(append(events, events[0])), NewPayloadDTO(decodeLines(t, buf.Bytes())))
+	})
+
+	t.Run("unsupported event", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewWriterSink(&buf).Send([]UserEvent{baseUserEvent{}})
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, buf.Len())
+	})
+}
+
+func TestFileSink(t *testing.T) {
+
+	t.Run("append events to file", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+		filename := filepath.Join(dir, "events.ndjson")
+
+		events := storedEvents()
+		sut, err := NewFileSink(filename)
+		assert.Nil(t, err)
+		assert.Nil(t, sut.Send(events[:1]))
+		assert.Nil(t, sut.Close())
+
+		sut, _ = NewFileSink(filename)
+		assert.Nil(t, sut.Send(events[1:]))
+		assert.Nil(t, sut.Close())
+
+		data, _ := ioutil.ReadFile(filename)
+		assert.Equal(t, NewPayloadDTO(events), NewPayloadDTO(decodeLines(t, data)))
+	})
+
+	t.Run("when file cannot be opened then return error", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		_, err := NewFileSink(filepath.Join(dir, "missing", "events.ndjson"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSinkDispatcher(t *testing.T) {
+
+	t.Run("send events to sink", func(t *testing.T) {
+		sink := &mockSink{}
+		sut := NewSinkDispatcher(sink, 0)
+
+		errs := make(chan error, 2)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		assert.Nil(t, <-errs)
+		assert.Nil(t, <-errs)
+
+		sut.Close()
+		assert.Equal(t, 2, sink.ReceivedCount())
+		assert.Equal(t, true, sink.closed)
+		assert.Equal(t, 0, sut.InFlightCount())
+		assert.Equal(t, 0, sut.QueuedCount())
+	})
+
+	t.Run("when sink fails then done with error", func(t *testing.T) {
+		sut := NewSinkDispatcher(&mockSink{err: errors.New("fail")}, 0)
+
+		errs := make(chan error, 1)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		assert.Equal(t, "fail", (<-errs).Error())
+		sut.Close()
+	})
+
+	t.Run("when queue is full then done with error and count dropped events", func(t *testing.T) {
+		sink := &mockSink{block: make(chan struct{})}
+		sut := NewSinkDispatcher(sink, 1)
+
+		errs := make(chan error, 3)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		time.Sleep(10 * time.Millisecond)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+
+		assert.Equal(t, "event sink queue is full", (<-errs).Error())
+		assert.Equal(t, int64(len(storedEvents())), sut.DroppedCount())
+		close(sink.block)
+		assert.Nil(t, <-errs)
+		assert.Nil(t, <-errs)
+		sut.Close()
+	})
+
+	t.Run("when sink panics then done with error", func(t *testing.T) {
+		sut := NewSinkDispatcher(&mockSink{panics: true}, 0)
+
+		errs := make(chan error, 1)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+		assert.Contains(t, (<-errs).Error(), "sink panic")
+		sut.Close()
+	})
+}
+
+func TestFanOutDispatcher(t *testing.T) {
+
+	t.Run("dispatch to every dispatcher and done after all completed", func(t *testing.T) {
+		slow := &mockDispatcher{delay: 50 * time.Millisecond}
+		sink := &mockSink{}
+		sut := NewFanOutDispatcher(slow, NewSinkDispatcher(sink, 0))
+
+		errs := make(chan error, 1)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+
+		assert.Nil(t, <-errs)
+		assert.Equal(t, 1, slow.DispatchCount())
+		assert.Equal(t, 1, sink.ReceivedCount())
+
+		sut.Close()
+		assert.Equal(t, true, slow.closed)
+		assert.Equal(t, true, sink.closed)
+	})
+
+	t.Run("done with error of primary dispatcher", func(t *testing.T) {
+		hackle := &mockDispatcher{err: errors.New("hackle")}
+		sink := &mockSink{}
+		sut := NewFanOutDispatcher(hackle, NewSinkDispatcher(sink, 0))
+
+		errs := make(chan error, 1)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+
+		assert.Equal(t, "hackle", (<-errs).Error())
+		assert.Equal(t, 1, sink.ReceivedCount())
+		sut.Close()
+	})
+
+	t.Run("when mirror sink fails then done without error", func(t *testing.T) {
+		hackle := &mockDispatcher{}
+		sut := NewFanOutDispatcher(hackle, NewSinkDispatcher(&mockSink{err: errors.New("fail")}, 0))
+
+		errs := make(chan error, 1)
+		sut.Dispatch(storedEvents(), func(err error) { errs <- err })
+
+		assert.Nil(t, <-errs)
+		assert.Equal(t, 1, hackle.DispatchCount())
+		sut.Close()
+	})
+}
@@ -0,0 +1,362 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type Store interface {
+	Append(event UserEvent) (segment string, ok bool)
+	Seal() (segment string, ok bool)
+	Remove(segment string)
+	Recover() []StoredBatch
+	Close()
+}
+
+type StoredBatch struct {
+	Segment string
+	Events  []UserEvent
+}
+
+var segmentPattern = regexp.MustCompile(`^(\d{20})\.seg$`)
+
+func NewFileStore(dir string, maxBytes int64) Store {
+	return &fileStore{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+type segmentFile struct {
+	name string
+	size int64
+}
+
+type fileStore struct {
+	dir         string
+	maxBytes    int64
+	segments    []segmentFile
+	current     *os.File
+	currentName string
+	currentSize int64
+	nextSeq     int64
+	initialized bool
+	mu          sync.Mutex
+}
+
+func (s *fileStore) Append(event UserEvent) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.init(); err != nil {
+		logger.Warn("failed to initialize event store: %v", err)
+		return "", false
+	}
+
+	record, err := encodeRecord(event)
+	if err != nil {
+		logger.Warn("failed to encode event record: %v", err)
+		return "", false
+	}
+	if !s.ensureCapacity(int64(len(record))) {
+		logger.Warn("Event not persisted. Exceed event store size %d bytes.", s.maxBytes)
+		return "", false
+	}
+	if s.current == nil {
+		if err := s.openSegment(); err != nil {
+			logger.Warn("failed to open event segment: %v", err)
+			return "", false
+		}
+	}
+	n, err := s.current.Write(record)
+	s.currentSize += int64(n)
+	if err != nil {
+		logger.Warn("failed to write event record: %v", err)
+		return "", false
+	}
+	return s.currentName, true
+}
+
+func (s *fileStore) Seal() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return "", false
+	}
+	name := s.currentName
+	s.segments = append(s.segments, segmentFile{name: name, size: s.currentSize})
+	s.closeSegment()
+	return name, true
+}
+
+func (s *fileStore) Remove(segment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, it := range s.segments {
+		if it.name == segment {
+			s.segments = append(s.segments[:i], s.segments[i+1:]...)
+			break
+		}
+	}
+	s.removeFile(segment)
+}
+
+func (s *fileStore) Recover() []StoredBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.init(); err != nil {
+		logger.Warn("failed to initialize event store: %v", err)
+		return nil
+	}
+
+	var batches []StoredBatch
+	var segments []segmentFile
+	for _, segment := range s.segments {
+		events, corrupted, err := s.readSegment(segment.name)
+		if err != nil {
+			logger.Warn("failed to read event segment %s: %v", segment.name, err)
+			continue
+		}
+		if corrupted > 0 {
+			logger.Warn("Skipped %d corrupted records in event segment %s.", corrupted, segment.name)
+		}
+		if len(events) == 0 {
+			s.removeFile(segment.name)
+			continue
+		}
+		segments = append(segments, segment)
+		batches = append(batches, StoredBatch{Segment: segment.name, Events: events})
+	}
+	s.segments = segments
+	if len(batches) > 0 {
+		logger.Info("Recovered %d undelivered event batches from %s.", len(batches), s.dir)
+	}
+	return batches
+}
+
+func (s *fileStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		s.segments = append(s.segments, segmentFile{name: s.currentName, size: s.currentSize})
+		s.closeSegment()
+	}
+}
+
+func (s *fileStore) init() error {
+	if s.initialized {
+		return nil
+	}
+	err := os.MkdirAll(s.dir, 0755)
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var segments []segmentFile
+	for _, file := range files {
+		match := segmentPattern.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		seq, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+		segments = append(segments, segmentFile{name: file.Name(), size: file.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].name < segments[j].name
+	})
+	s.segments = segments
+	s.initialized = true
+	return nil
+}
+
+func (s *fileStore) ensureCapacity(size int64) bool {
+	for s.totalSize()+size > s.maxBytes && len(s.segments) > 0 {
+		oldest := s.segments[0]
+		s.segments = s.segments[1:]
+		s.removeFile(oldest.name)
+		logger.Warn("Event store exceeded %d bytes. Removed oldest event segment %s.", s.maxBytes, oldest.name)
+	}
+	return s.totalSize()+size <= s.maxBytes
+}
+
+func (s *fileStore) totalSize() int64 {
+	size := s.currentSize
+	for _, segment := range s.segments {
+		size += segment.size
+	}
+	return size
+}
+
+func (s *fileStore) openSegment() error {
+	name := fmt.Sprintf("%020d.seg", s.nextSeq)
+	file, err := os.OpenFile(filepath.Join(s.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.nextSeq++
+	s.current = file
+	s.currentName = name
+	s.currentSize = 0
+	return nil
+}
+
+func (s *fileStore) closeSegment() {
+	if err := s.current.Sync(); err != nil {
+		logger.Warn("failed to sync event segment: %v", err)
+	}
+	if err := s.current.Close(); err != nil {
+		logger.Warn("failed to close event segment: %v", err)
+	}
+	s.current = nil
+	s.currentName = ""
+	s.currentSize = 0
+}
+
+func (s *fileStore) removeFile(segment string) {
+	err := os.Remove(filepath.Join(s.dir, segment))
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warn("failed to remove event segment: %v", err)
+	}
+}
+
+func (s *fileStore) readSegment(segment string) ([]UserEvent, int, error) {
+	file, err := os.Open(filepath.Join(s.dir, segment))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		e := file.Close()
+		if e != nil {
+			logger.Warn("failed to close event segment: %v", e)
+		}
+	}()
+
+	var events []UserEvent
+	corrupted := 0
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			event, e := decodeRecord(line)
+			if e != nil {
+				corrupted++
+			} else {
+				events = append(events, event)
+			}
+		}
+		if err == io.EOF {
+			return events, corrupted, nil
+		}
+		if err != nil {
+			return events, corrupted, err
+		}
+	}
+}
+
+type recordDTO struct {
+	Exposure     *ExposureEventDTO     `json:"exposure,omitempty"`
+	Track        *TrackEventDTO        `json:"track,omitempty"`
+	RemoteConfig *RemoteConfigEventDTO `json:"remoteConfig,omitempty"`
+}
+
+func newRecordDTO(userEvent UserEvent) (recordDTO, error) {
+	var record recordDTO
+	switch event := userEvent.(type) {
+	case ExposureEvent:
+		dto := NewExposureEventDTO(event)
+		record.Exposure = &dto
+	case TrackEvent:
+		dto := NewTrackEventDTO(event)
+		record.Track = &dto
+	case RemoteConfigEvent:
+		dto := NewRemoteConfigEventDTO(event)
+		record.RemoteConfig = &dto
+	default:
+		return record, fmt.Errorf("unsupported event type: %T", userEvent)
+	}
+	return record, nil
+}
+
+func encodeRecord(userEvent UserEvent) ([]byte, error) {
+	record, err := newRecordDTO(userEvent)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(data), data)), nil
+}
+
+func decodeRecord(line []byte) (UserEvent, error) {
+	if len(line) < 10 || line[8] != ' ' || line[len(line)-1] != '\n' {
+		return nil, errors.New("malformed record")
+	}
+	checksum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(line[9:], []byte("\n"))
+	if crc32.ChecksumIEEE(data) != uint32(checksum) {
+		return nil, errors.New("checksum mismatch")
+	}
+	var record recordDTO
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case record.Exposure != nil:
+		return record.Exposure.toEvent(), nil
+	case record.Track != nil:
+		return record.Track.toEvent(), nil
+	case record.RemoteConfig != nil:
+		return record.RemoteConfig.toEvent(), nil
+	default:
+		return nil, errors.New("empty record")
+	}
+}
+
+func NewNoopStore() Store {
+	return &noopStore{}
+}
+
+type noopStore struct{}
+
+func (s *noopStore) Append(event UserEvent) (string, bool) {
+	return "", false
+}
+
+func (s *noopStore) Seal() (string, bool) {
+	return "", false
+}
+
+func (s *noopStore) Remove(segment string) {}
+
+func (s *noopStore) Recover() []StoredBatch {
+	return nil
+}
+
+func (s *noopStore) Close() {}
@@ -0,0 +1,319 @@
+package event
+
+import (
+	"errors"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/ref"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func storedEvents() []UserEvent {
+	hackleUser := user.HackleUser{
+		Identifiers: map[string]string{"$id": "id"},
+		Properties:  map[string]interface{}{"age": 42.0},
+	}
+	return []UserEvent{
+		ExposureEvent{
+			UserEvent:      baseUserEvent{insertID: "exposure", timestamp: 1, user: hackleUser},
+			Experiment:     model.Experiment{ID: 1, Key: 2, Type: model.ExperimentTypeAbTest, Version: 3},
+			VariationID:    ref.Int64(4),
+			VariationKey:   "A",
+			DecisionReason: "TRAFFIC_ALLOCATED",
+			Properties:     map[string]interface{}{"$experiment_version": 3.0},
+		},
+		TrackEvent{
+			UserEvent: baseUserEvent{insertID: "track", timestamp: 2, user: hackleUser},
+			EventType: model.EventType{ID: 101, Key: "purchase"},
+			Event:     event{key: "purchase", value: 42.0, properties: map[string]interface{}{"a": "b"}},
+		},
+		RemoteConfigEvent{
+			UserEvent:      baseUserEvent{insertID: "rc", timestamp: 3, user: hackleUser},
+			Parameter:      model.RemoteConfigParameter{ID: 5, Key: "rc", Type: types.String},
+			ValueID:        ref.Int64(6),
+			DecisionReason: "DEFAULT_RULE",
+			Properties:     map[string]interface{}{"returnValue": "v"},
+		},
+	}
+}
+
+func segmentFiles(dir string) []string {
+	files, _ := ioutil.ReadDir(dir)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	return names
+}
+
+func TestFileStore(t *testing.T) {
+
+	t.Run("when sealed segment not removed then recover on next start", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		events := storedEvents()
+		sut := NewFileStore(filepath.Join(dir, "events"), 1<<20)
+		assert.Equal(t, 0, len(sut.Recover()))
+		for _, e := range events {
+			sut.Append(e)
+		}
+		segment, ok := sut.Seal()
+		assert.Equal(t, true, ok)
+		sut.Append(events[0])
+		sut.Close()
+
+		recovered := NewFileStore(filepath.Join(dir, "events"), 1<<20).Recover()
+		assert.Equal(t, 2, len(recovered))
+		assert.Equal(t, segment, recovered[0].Segment)
+		assert.Equal(t, NewPayloadDTO(events), NewPayloadDTO(recovered[0].Events))
+		assert.Equal(t, NewPayloadDTO(events[:1]), NewPayloadDTO(recovered[1].Events))
+	})
+
+	t.Run("when segment removed then do not recover", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		sut := NewFileStore(dir, 1<<20)
+		sut.Append(storedEvents()[0])
+		segment, _ := sut.Seal()
+		sut.Remove(segment)
+		sut.Remove(segment)
+
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+		assert.Equal(t, 0, len(NewFileStore(dir, 1<<20).Recover()))
+	})
+
+	t.Run("when nothing appended then seal nothing", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		_, ok := NewFileStore(dir, 1<<20).Seal()
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("new segments continue sequence of existing segments", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		sut := NewFileStore(dir, 1<<20)
+		sut.Append(storedEvents()[0])
+		first, _ := sut.Seal()
+		sut.Close()
+
+		sut = NewFileStore(dir, 1<<20)
+		sut.Append(storedEvents()[0])
+		second, _ := sut.Seal()
+
+		assert.Equal(t, "00000000000000000000.seg", first)
+		assert.Equal(t, "00000000000000000001.seg", second)
+	})
+
+	t.Run("when size exceeded then remove oldest segments", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		record, _ := encodeRecord(storedEvents()[1])
+		sut := NewFileStore(dir, int64(len(record)*2))
+		for i := 0; i < 3; i++ {
+			sut.Append(storedEvents()[1])
+			sut.Seal()
+		}
+
+		assert.Equal(t, []string{"00000000000000000001.seg", "00000000000000000002.seg"}, segmentFiles(dir))
+	})
+
+	t.Run("when record larger than max size then do not persist", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		sut := NewFileStore(dir, 10)
+		sut.Append(storedEvents()[1])
+		_, ok := sut.Seal()
+
+		assert.Equal(t, false, ok)
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+	})
+
+	t.Run("skip corrupted records", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		events := storedEvents()
+		sut := NewFileStore(dir, 1<<20)
+		sut.Append(events[0])
+		sut.Append(events[1])
+		segment, _ := sut.Seal()
+		sut.Close()
+
+		filename := filepath.Join(dir, segment)
+		data, _ := ioutil.ReadFile(filename)
+		data[0] = 'x'
+		data = append(data, []byte("00000000 {\"track\":{}}\n")...)
+		data = append(data, []byte("garbage\n")...)
+		record, _ := encodeRecord(events[2])
+		data = append(data, record[:len(record)-5]...)
+		_ = ioutil.WriteFile(filename, data, 0644)
+
+		recovered := NewFileStore(dir, 1<<20).Recover()
+		assert.Equal(t, 1, len(recovered))
+		assert.Equal(t, NewPayloadDTO(events[1:2]), NewPayloadDTO(recovered[0].Events))
+	})
+
+	t.Run("when segment has no valid records then remove it", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		_ = ioutil.WriteFile(filepath.Join(dir, "00000000000000000000.seg"), []byte("garbage"), 0644)
+		_ = ioutil.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0644)
+
+		recovered := NewFileStore(dir, 1<<20).Recover()
+		assert.Equal(t, 0, len(recovered))
+		assert.Equal(t, []string{"other.txt"}, segmentFiles(dir))
+	})
+}
+
+func TestNoopStore(t *testing.T) {
+	sut := NewNoopStore()
+	sut.Append(baseUserEvent{})
+	_, ok := sut.Seal()
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 0, len(sut.Recover()))
+	sut.Remove("segment")
+	sut.Close()
+}
+
+func TestProcessor_store(t *testing.T) {
+
+	t.Run("replay undelivered batches on next start", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		events := storedEvents()
+		crashed := NewFileStore(dir, 1<<20)
+		for _, e := range events {
+			crashed.Append(e)
+		}
+		crashed.Seal()
+		crashed.Append(events[0])
+
+		dispatcher := &mockDispatcher{}
+		sut := NewProcessor(100, OverflowDropNewest, time.Second, dispatcher, 10, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		sut.Start()
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, 2, dispatcher.DispatchCount())
+		assert.Equal(t, 4, dispatcher.EventCount())
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+		sut.Close()
+	})
+
+	t.Run("when dispatch failed then replay on next start", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		failing := &mockDispatcher{err: errors.New("dispatch failed")}
+		sut := NewProcessor(100, OverflowDropNewest, time.Second, failing, 2, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		sut.Start()
+		sut.Process(storedEvents()[0])
+		sut.Process(storedEvents()[1])
+		time.Sleep(50 * time.Millisecond)
+		sut.Close()
+		assert.Equal(t, 1, failing.DispatchCount())
+		assert.Equal(t, 1, len(segmentFiles(dir)))
+
+		dispatcher := &mockDispatcher{}
+		restarted := NewProcessor(100, OverflowDropNewest, time.Second, dispatcher, 2, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		restarted.Start()
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(t, 1, dispatcher.DispatchCount())
+		assert.Equal(t, 2, dispatcher.EventCount())
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+		restarted.Close()
+	})
+
+	t.Run("when dispatch failed and dead lettered then do not replay", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		dispatcher := &mockDispatcher{err: &DeadLetteredError{Err: errors.New("dispatch failed")}}
+		sut := NewProcessor(100, OverflowDropNewest, time.Second, dispatcher, 2, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		sut.Start()
+		sut.Process(storedEvents()[0])
+		sut.Process(storedEvents()[1])
+		time.Sleep(50 * time.Millisecond)
+		sut.Close()
+
+		assert.Equal(t, 1, dispatcher.DispatchCount())
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+	})
+
+	t.Run("when killed with full queue then replay queued events on next start", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		killed := NewProcessor(3, OverflowDropNewest, time.Second, &mockDispatcher{}, 100, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		for _, e := range storedEvents() {
+			killed.Process(e)
+		}
+		assert.Equal(t, 1, len(segmentFiles(dir)))
+
+		dispatcher := &mockDispatcher{}
+		restarted := NewProcessor(3, OverflowDropNewest, time.Second, dispatcher, 100, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		restarted.Start()
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(t, 1, dispatcher.DispatchCount())
+		assert.Equal(t, NewPayloadDTO(storedEvents()), NewPayloadDTO(dispatcher.DispatchedEvents()))
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+		restarted.Close()
+	})
+
+	t.Run("keep sealed segment until its queued events are delivered", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		sut, f := eventProcessor(10, 2, 10*time.Second)
+		sut.store = NewFileStore(dir, 1<<20)
+		for _, e := range storedEvents() {
+			sut.Process(e)
+		}
+
+		batch := sut.consume(nil, <-f.queue)
+		sut.consume(batch, <-f.queue)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, f.dispatcher.DispatchCount())
+		assert.Equal(t, 1, len(segmentFiles(dir)))
+
+		sut.dispatch(sut.consume(nil, <-f.queue))
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 2, f.dispatcher.DispatchCount())
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+	})
+
+	t.Run("remove segment after dispatch", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		dispatcher := &mockDispatcher{}
+		sut := NewProcessor(100, OverflowDropNewest, time.Second, dispatcher, 2, &mockScheduler{}, 10*time.Second, NewFileStore(dir, 1<<20))
+		sut.Start()
+
+		sut.Process(storedEvents()[0])
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, len(segmentFiles(dir)))
+
+		sut.Process(storedEvents()[1])
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, dispatcher.DispatchCount())
+		assert.Equal(t, 0, len(segmentFiles(dir)))
+		sut.Close()
+	})
+}
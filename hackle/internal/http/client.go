@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 	"net/http"
@@ -12,13 +13,11 @@ type Client interface {
 	Execute(req *http.Request) (*http.Response, error)
 }
 
-func NewClient(sdk model.Sdk, clock clock.Clock, timeout time.Duration) Client {
+func NewClient(sdk model.Sdk, clock clock.Clock, client *http.Client) Client {
 	return &httpClient{
-		delegate: &http.Client{
-			Timeout: timeout,
-		},
-		sdk:   sdk,
-		clock: clock,
+		delegate: client,
+		sdk:      sdk,
+		clock:    clock,
 	}
 }
 
@@ -51,3 +50,38 @@ func IsSuccessful(res *http.Response) bool {
 func IsNotModified(res *http.Response) bool {
 	return res.StatusCode == 304
 }
+
+type ResponseError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func NewResponseError(res *http.Response) *ResponseError {
+	err := &ResponseError{StatusCode: res.StatusCode}
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter = retryAfter(res.Header.Get("Retry-After"))
+	}
+	return err
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("http status code: %d", e.StatusCode)
+}
+
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -10,11 +10,12 @@ import (
 )
 
 func TestNewClient(t *testing.T) {
-	client := NewClient(model.Sdk{}, clock.System, 10*time.Second)
+	delegate := &http.Client{Timeout: 10 * time.Second}
+	client := NewClient(model.Sdk{}, clock.System, delegate)
 	assert.IsType(t, &httpClient{}, client)
 
 	hc := client.(*httpClient)
-	assert.IsType(t, &http.Client{}, hc.delegate)
+	assert.Same(t, delegate, hc.delegate)
 }
 
 func TestHttpClient_Execute(t *testing.T) {
@@ -54,6 +55,29 @@ func TestIsNotModified(t *testing.T) {
 	assert.Equal(t, false, IsNotModified(&http.Response{StatusCode: 500}))
 }
 
+func TestNewResponseError(t *testing.T) {
+	response := func(statusCode int, retryAfter string) *http.Response {
+		res := &http.Response{StatusCode: statusCode, Header: http.Header{}}
+		if retryAfter != "" {
+			res.Header.Set("Retry-After", retryAfter)
+		}
+		return res
+	}
+
+	assert.Equal(t, &ResponseError{StatusCode: 500}, NewResponseError(response(500, "")))
+	assert.Equal(t, "http status code: 500", NewResponseError(response(500, "")).Error())
+	assert.Equal(t, &ResponseError{StatusCode: 500}, NewResponseError(response(500, "30")))
+	assert.Equal(t, &ResponseError{StatusCode: 429, RetryAfter: 30 * time.Second}, NewResponseError(response(429, "30")))
+	assert.Equal(t, &ResponseError{StatusCode: 503, RetryAfter: 120 * time.Second}, NewResponseError(response(503, "120")))
+	assert.Equal(t, &ResponseError{StatusCode: 503}, NewResponseError(response(503, "-1")))
+	assert.Equal(t, &ResponseError{StatusCode: 503}, NewResponseError(response(503, "invalid")))
+	assert.Equal(t, &ResponseError{StatusCode: 503}, NewResponseError(response(503, "Wed, 21 Oct 2015 07:28:00 GMT")))
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	actual := NewResponseError(response(503, date)).RetryAfter
+	assert.True(t, actual > 59*time.Minute && actual <= time.Hour)
+}
+
 type mockHttpClient struct {
 	req     *http.Request
 	returns interface{}
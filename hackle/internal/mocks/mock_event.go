@@ -4,6 +4,10 @@ func CreateEvent(key string) Event {
 	return Event{key: key}
 }
 
+func CreateEventWithValue(key string, value float64) Event {
+	return Event{key: key, value: value}
+}
+
 type Event struct {
 	key        string
 	value      float64
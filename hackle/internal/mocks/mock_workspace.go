@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"sort"
 )
 
 type MockWorkspace struct {
@@ -68,6 +69,42 @@ func (m *MockWorkspace) GetRemoteConfigParameter(parameterKey string) (model.Rem
 	return remoteConfigParameter, ok
 }
 
+func (m *MockWorkspace) GetExperiments() []model.Experiment {
+	experiments := make([]model.Experiment, 0, len(m.experiments))
+	for _, it := range m.experiments {
+		experiments = append(experiments, it)
+	}
+	sort.Slice(experiments, func(i, j int) bool { return experiments[i].Key < experiments[j].Key })
+	return experiments
+}
+
+func (m *MockWorkspace) GetFeatureFlags() []model.Experiment {
+	featureFlags := make([]model.Experiment, 0, len(m.featureFlags))
+	for _, it := range m.featureFlags {
+		featureFlags = append(featureFlags, it)
+	}
+	sort.Slice(featureFlags, func(i, j int) bool { return featureFlags[i].Key < featureFlags[j].Key })
+	return featureFlags
+}
+
+func (m *MockWorkspace) GetSegments() []model.Segment {
+	segments := make([]model.Segment, 0, len(m.segments))
+	for _, it := range m.segments {
+		segments = append(segments, it)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Key < segments[j].Key })
+	return segments
+}
+
+func (m *MockWorkspace) GetRemoteConfigParameters() []model.RemoteConfigParameter {
+	parameters := make([]model.RemoteConfigParameter, 0, len(m.remoteConfigParameters))
+	for _, it := range m.remoteConfigParameters {
+		parameters = append(parameters, it)
+	}
+	sort.Slice(parameters, func(i, j int) bool { return parameters[i].Key < parameters[j].Key })
+	return parameters
+}
+
 func (m *MockWorkspace) Experiment(experiment model.Experiment) *MockWorkspace {
 	m.experiments[experiment.Key] = experiment
 	return m
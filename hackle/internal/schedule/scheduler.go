@@ -4,6 +4,7 @@ import "time"
 
 type Scheduler interface {
 	SchedulePeriodically(period time.Duration, task func()) Job
+	SchedulePeriodicallyWithDelay(initialDelay time.Duration, period time.Duration, task func()) Job
 }
 
 type Job interface {
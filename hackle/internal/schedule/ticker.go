@@ -11,13 +11,26 @@ func NewTickerScheduler() Scheduler {
 }
 
 func (s *tickerScheduler) SchedulePeriodically(period time.Duration, task func()) Job {
+	return s.SchedulePeriodicallyWithDelay(period, period, task)
+}
+
+func (s *tickerScheduler) SchedulePeriodicallyWithDelay(initialDelay time.Duration, period time.Duration, task func()) Job {
 	job := &tickerJob{
 		stop: make(chan bool),
 	}
 
-	ticker := time.NewTicker(period)
+	timer := time.NewTimer(initialDelay)
 	go func() {
+		select {
+		case <-timer.C:
+		case <-job.stop:
+			timer.Stop()
+			return
+		}
+
+		ticker := time.NewTicker(period)
 		defer ticker.Stop()
+		task()
 		for {
 			select {
 			case <-ticker.C:
@@ -46,6 +46,34 @@ func TestTickerScheduler(t *testing.T) {
 	})
 }
 
+func TestTickerScheduler_SchedulePeriodicallyWithDelay(t *testing.T) {
+
+	t.Run("schedule after initial delay", func(t *testing.T) {
+		c := &counter{}
+		scheduler := NewTickerScheduler()
+		job := scheduler.SchedulePeriodicallyWithDelay(100*time.Millisecond, 500*time.Millisecond, func() {
+			c.increment()
+		})
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, 1, c.get())
+		time.Sleep(500 * time.Millisecond)
+		job.Cancel()
+		assert.Equal(t, 2, c.get())
+	})
+
+	t.Run("cancel during initial delay", func(t *testing.T) {
+		c := &counter{}
+		scheduler := NewTickerScheduler()
+		job := scheduler.SchedulePeriodicallyWithDelay(500*time.Millisecond, 100*time.Millisecond, func() {
+			c.increment()
+		})
+		time.Sleep(100 * time.Millisecond)
+		job.Cancel()
+		time.Sleep(500 * time.Millisecond)
+		assert.Equal(t, 0, c.get())
+	})
+}
+
 type counter struct {
 	count int
 	mu    sync.Mutex
@@ -0,0 +1,192 @@
+package workspace
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"reflect"
+)
+
+type ChangeType string
+
+const (
+	ChangeTypeAdded    ChangeType = "ADDED"
+	ChangeTypeRemoved  ChangeType = "REMOVED"
+	ChangeTypeModified ChangeType = "MODIFIED"
+)
+
+type Change struct {
+	Experiments            []ExperimentChange
+	FeatureFlags           []ExperimentChange
+	Segments               []SegmentChange
+	RemoteConfigParameters []RemoteConfigParameterChange
+}
+
+func (c Change) IsEmpty() bool {
+	return len(c.Experiments) == 0 &&
+		len(c.FeatureFlags) == 0 &&
+		len(c.Segments) == 0 &&
+		len(c.RemoteConfigParameters) == 0
+}
+
+type ExperimentChange struct {
+	Type                ChangeType
+	ID                  int64
+	Key                 int64
+	OldStatus           string
+	NewStatus           string
+	OldVersion          int
+	NewVersion          int
+	OldExecutionVersion int
+	NewExecutionVersion int
+}
+
+type SegmentChange struct {
+	Type ChangeType
+	ID   int64
+	Key  string
+}
+
+type RemoteConfigParameterChange struct {
+	Type ChangeType
+	ID   int64
+	Key  string
+}
+
+func Diff(oldWorkspace Workspace, newWorkspace Workspace) Change {
+	return Change{
+		Experiments:            diffExperiments(oldWorkspace, newWorkspace, Workspace.GetExperiments),
+		FeatureFlags:           diffExperiments(oldWorkspace, newWorkspace, Workspace.GetFeatureFlags),
+		Segments:               diffSegments(oldWorkspace, newWorkspace),
+		RemoteConfigParameters: diffRemoteConfigParameters(oldWorkspace, newWorkspace),
+	}
+}
+
+func diffExperiments(oldWorkspace Workspace, newWorkspace Workspace, get func(Workspace) []model.Experiment) []ExperimentChange {
+	var oldItems, newItems []model.Experiment
+	if oldWorkspace != nil {
+		oldItems = get(oldWorkspace)
+	}
+	if newWorkspace != nil {
+		newItems = get(newWorkspace)
+	}
+
+	olds := make(map[int64]model.Experiment, len(oldItems))
+	for _, it := range oldItems {
+		olds[it.Key] = it
+	}
+	news := make(map[int64]model.Experiment, len(newItems))
+	for _, it := range newItems {
+		news[it.Key] = it
+	}
+
+	changes := make([]ExperimentChange, 0)
+	for _, it := range oldItems {
+		if _, ok := news[it.Key]; !ok {
+			changes = append(changes, ExperimentChange{
+				Type:                ChangeTypeRemoved,
+				ID:                  it.ID,
+				Key:                 it.Key,
+				OldStatus:           string(it.Status),
+				OldVersion:          it.Version,
+				OldExecutionVersion: it.ExecutionVersion,
+			})
+		}
+	}
+	for _, it := range newItems {
+		o, ok := olds[it.Key]
+		if !ok {
+			changes = append(changes, ExperimentChange{
+				Type:                ChangeTypeAdded,
+				ID:                  it.ID,
+				Key:                 it.Key,
+				NewStatus:           string(it.Status),
+				NewVersion:          it.Version,
+				NewExecutionVersion: it.ExecutionVersion,
+			})
+			continue
+		}
+		if !reflect.DeepEqual(o, it) {
+			changes = append(changes, ExperimentChange{
+				Type:                ChangeTypeModified,
+				ID:                  it.ID,
+				Key:                 it.Key,
+				OldStatus:           string(o.Status),
+				NewStatus:           string(it.Status),
+				OldVersion:          o.Version,
+				NewVersion:          it.Version,
+				OldExecutionVersion: o.ExecutionVersion,
+				NewExecutionVersion: it.ExecutionVersion,
+			})
+		}
+	}
+	return changes
+}
+
+func diffSegments(oldWorkspace Workspace, newWorkspace Workspace) []SegmentChange {
+	var oldItems, newItems []model.Segment
+	if oldWorkspace != nil {
+		oldItems = oldWorkspace.GetSegments()
+	}
+	if newWorkspace != nil {
+		newItems = newWorkspace.GetSegments()
+	}
+
+	olds := make(map[string]model.Segment, len(oldItems))
+	for _, it := range oldItems {
+		olds[it.Key] = it
+	}
+	news := make(map[string]model.Segment, len(newItems))
+	for _, it := range newItems {
+		news[it.Key] = it
+	}
+
+	changes := make([]SegmentChange, 0)
+	for _, it := range oldItems {
+		if _, ok := news[it.Key]; !ok {
+			changes = append(changes, SegmentChange{Type: ChangeTypeRemoved, ID: it.ID, Key: it.Key})
+		}
+	}
+	for _, it := range newItems {
+		o, ok := olds[it.Key]
+		if !ok {
+			changes = append(changes, SegmentChange{Type: ChangeTypeAdded, ID: it.ID, Key: it.Key})
+		} else if !reflect.DeepEqual(o, it) {
+			changes = append(changes, SegmentChange{Type: ChangeTypeModified, ID: it.ID, Key: it.Key})
+		}
+	}
+	return changes
+}
+
+func diffRemoteConfigParameters(oldWorkspace Workspace, newWorkspace Workspace) []RemoteConfigParameterChange {
+	var oldItems, newItems []model.RemoteConfigParameter
+	if oldWorkspace != nil {
+		oldItems = oldWorkspace.GetRemoteConfigParameters()
+	}
+	if newWorkspace != nil {
+		newItems = newWorkspace.GetRemoteConfigParameters()
+	}
+
+	olds := make(map[string]model.RemoteConfigParameter, len(oldItems))
+	for _, it := range oldItems {
+		olds[it.Key] = it
+	}
+	news := make(map[string]model.RemoteConfigParameter, len(newItems))
+	for _, it := range newItems {
+		news[it.Key] = it
+	}
+
+	changes := make([]RemoteConfigParameterChange, 0)
+	for _, it := range oldItems {
+		if _, ok := news[it.Key]; !ok {
+			changes = append(changes, RemoteConfigParameterChange{Type: ChangeTypeRemoved, ID: it.ID, Key: it.Key})
+		}
+	}
+	for _, it := range newItems {
+		o, ok := olds[it.Key]
+		if !ok {
+			changes = append(changes, RemoteConfigParameterChange{Type: ChangeTypeAdded, ID: it.ID, Key: it.Key})
+		} else if !reflect.DeepEqual(o, it) {
+			changes = append(changes, RemoteConfigParameterChange{Type: ChangeTypeModified, ID: it.ID, Key: it.Key})
+		}
+	}
+	return changes
+}
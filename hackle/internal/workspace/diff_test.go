@@ -0,0 +1,96 @@
+package workspace
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/mocks"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+
+	t.Run("when workspaces are same then empty", func(t *testing.T) {
+		ws, _ := NewFileFetcher("../../../testdata/workspace_config.json").Fetch()
+		actual := Diff(ws, ws)
+		assert.Equal(t, true, actual.IsEmpty())
+	})
+
+	t.Run("when old workspace is nil then all added", func(t *testing.T) {
+		ws, _ := NewFileFetcher("../../../testdata/workspace_config.json").Fetch()
+		actual := Diff(nil, ws)
+		assert.Equal(t, 7, len(actual.Experiments))
+		assert.Equal(t, 4, len(actual.FeatureFlags))
+		for _, it := range actual.Experiments {
+			assert.Equal(t, ChangeTypeAdded, it.Type)
+		}
+	})
+
+	t.Run("experiments", func(t *testing.T) {
+		oldWorkspace := mocks.CreateWorkspace().
+			Experiment(model.Experiment{ID: 1, Key: 1, Status: model.ExperimentStatusRunning, Version: 1, ExecutionVersion: 1}).
+			Experiment(model.Experiment{ID: 2, Key: 2, Status: model.ExperimentStatusRunning, Version: 1, ExecutionVersion: 1}).
+			Experiment(model.Experiment{ID: 3, Key: 3, Status: model.ExperimentStatusRunning, Version: 1, ExecutionVersion: 1})
+		newWorkspace := mocks.CreateWorkspace().
+			Experiment(model.Experiment{ID: 2, Key: 2, Status: model.ExperimentStatusPaused, Version: 1, ExecutionVersion: 2}).
+			Experiment(model.Experiment{ID: 3, Key: 3, Status: model.ExperimentStatusRunning, Version: 1, ExecutionVersion: 1}).
+			Experiment(model.Experiment{ID: 4, Key: 4, Status: model.ExperimentStatusDraft, Version: 1, ExecutionVersion: 1})
+
+		actual := Diff(oldWorkspace, newWorkspace)
+
+		assert.Equal(t, []ExperimentChange{
+			{Type: ChangeTypeRemoved, ID: 1, Key: 1, OldStatus: "RUNNING", OldVersion: 1, OldExecutionVersion: 1},
+			{Type: ChangeTypeModified, ID: 2, Key: 2, OldStatus: "RUNNING", NewStatus: "PAUSED", OldVersion: 1, NewVersion: 1, OldExecutionVersion: 1, NewExecutionVersion: 2},
+			{Type: ChangeTypeAdded, ID: 4, Key: 4, NewStatus: "DRAFT", NewVersion: 1, NewExecutionVersion: 1},
+		}, actual.Experiments)
+		assert.Equal(t, 0, len(actual.FeatureFlags))
+		assert.Equal(t, false, actual.IsEmpty())
+	})
+
+	t.Run("feature flags", func(t *testing.T) {
+		oldWorkspace := mocks.CreateWorkspace().
+			FeatureFlag(model.Experiment{ID: 1, Key: 1, Status: model.ExperimentStatusRunning, Version: 1})
+		newWorkspace := mocks.CreateWorkspace().
+			FeatureFlag(model.Experiment{ID: 1, Key: 1, Status: model.ExperimentStatusRunning, Version: 2})
+
+		actual := Diff(oldWorkspace, newWorkspace)
+
+		assert.Equal(t, []ExperimentChange{
+			{Type: ChangeTypeModified, ID: 1, Key: 1, OldStatus: "RUNNING", NewStatus: "RUNNING", OldVersion: 1, NewVersion: 2},
+		}, actual.FeatureFlags)
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		oldWorkspace := mocks.CreateWorkspace().
+			Segment(model.Segment{ID: 1, Key: "a", Type: model.SegmentTypeUserId}).
+			Segment(model.Segment{ID: 2, Key: "b", Type: model.SegmentTypeUserId})
+		newWorkspace := mocks.CreateWorkspace().
+			Segment(model.Segment{ID: 2, Key: "b", Type: model.SegmentTypeUserProperty}).
+			Segment(model.Segment{ID: 3, Key: "c", Type: model.SegmentTypeUserId})
+
+		actual := Diff(oldWorkspace, newWorkspace)
+
+		assert.Equal(t, []SegmentChange{
+			{Type: ChangeTypeRemoved, ID: 1, Key: "a"},
+			{Type: ChangeTypeModified, ID: 2, Key: "b"},
+			{Type: ChangeTypeAdded, ID: 3, Key: "c"},
+		}, actual.Segments)
+	})
+
+	t.Run("remote config parameters", func(t *testing.T) {
+		oldWorkspace := mocks.CreateWorkspace().
+			RemoteConfigParameter(model.RemoteConfigParameter{ID: 1, Key: "a", Type: types.String}).
+			RemoteConfigParameter(model.RemoteConfigParameter{ID: 2, Key: "b", Type: types.String})
+		newWorkspace := mocks.CreateWorkspace().
+			RemoteConfigParameter(model.RemoteConfigParameter{ID: 2, Key: "b", Type: types.Number}).
+			RemoteConfigParameter(model.RemoteConfigParameter{ID: 3, Key: "c", Type: types.String})
+
+		actual := Diff(oldWorkspace, newWorkspace)
+
+		assert.Equal(t, []RemoteConfigParameterChange{
+			{Type: ChangeTypeRemoved, ID: 1, Key: "a"},
+			{Type: ChangeTypeModified, ID: 2, Key: "b"},
+			{Type: ChangeTypeAdded, ID: 3, Key: "c"},
+		}, actual.RemoteConfigParameters)
+	})
+}
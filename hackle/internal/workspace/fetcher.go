@@ -4,3 +4,23 @@ type Fetcher interface {
 	Fetch() (Workspace, bool)
 	Close()
 }
+
+type Readiness interface {
+	Ready() <-chan struct{}
+	Err() error
+}
+
+// UpdateListener is called with the change between the workspaces, computed once for all listeners.
+// It is called on the fetching goroutine, so it must not block.
+type UpdateListener func(oldWorkspace Workspace, newWorkspace Workspace, change Change)
+
+type Notifier interface {
+	AddUpdateListener(listener UpdateListener)
+}
+
+type RemoteFetcher interface {
+	Fetcher
+	Readiness
+	Notifier
+	Start()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"io/ioutil"
 	nethttp "net/http"
 )
 
@@ -13,10 +14,11 @@ type HttpFetcher interface {
 	FetchIfModified() (Workspace, bool, error)
 }
 
-func NewHttpFetcher(sdkUrl string, sdk model.Sdk, httpClient http.Client) HttpFetcher {
+func NewHttpFetcher(sdkUrl string, sdk model.Sdk, httpClient http.Client, snapshot Snapshot) HttpFetcher {
 	return &httpFetcher{
 		url:          sdkUrl + "/api/v2/workspaces/" + sdk.Key + "/config",
 		httpClient:   httpClient,
+		snapshot:     snapshot,
 		lastModified: nil,
 	}
 }
@@ -24,6 +26,7 @@ func NewHttpFetcher(sdkUrl string, sdk model.Sdk, httpClient http.Client) HttpFe
 type httpFetcher struct {
 	url          string
 	httpClient   http.Client
+	snapshot     Snapshot
 	lastModified *string
 }
 
@@ -63,17 +66,27 @@ func (f *httpFetcher) handleResponse(res *nethttp.Response) (Workspace, bool, er
 		return nil, false, nil
 	}
 	if !http.IsSuccessful(res) {
-		return nil, false, fmt.Errorf("http status code: %d", res.StatusCode)
+		return nil, false, http.NewResponseError(res)
 	}
 
 	lastModified := res.Header.Get("Last-Modified")
 	f.lastModified = &lastModified
 
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
 	var dto WorkspaceDTO
-	err := json.NewDecoder(res.Body).Decode(&dto)
+	err = json.Unmarshal(body, &dto)
 	if err != nil {
 		return nil, false, err
 	}
 
+	err = f.snapshot.Save(body)
+	if err != nil {
+		logger.Warn("failed to save workspace snapshot: %v", err)
+	}
+
 	return NewFrom(dto), true, nil
 }
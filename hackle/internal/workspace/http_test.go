@@ -12,7 +12,7 @@ import (
 )
 
 func TestNewHttpFetcher(t *testing.T) {
-	fetcher := NewHttpFetcher("localhost", model.Sdk{Key: "sdk_key"}, &mockHttpClient{})
+	fetcher := NewHttpFetcher("localhost", model.Sdk{Key: "sdk_key"}, &mockHttpClient{}, NewNoopSnapshot())
 	assert.IsType(t, &httpFetcher{}, fetcher)
 	assert.Equal(t, "localhost/api/v2/workspaces/sdk_key/config", fetcher.(*httpFetcher).url)
 }
@@ -157,6 +157,7 @@ func Test_httpFetcher_FetchIfModified(t *testing.T) {
 			sut := &httpFetcher{
 				url:          tt.fields.url,
 				httpClient:   tt.fields.httpClient,
+				snapshot:     NewNoopSnapshot(),
 				lastModified: tt.fields.lastModified,
 			}
 			ws, ok, err := sut.FetchIfModified()
@@ -165,6 +166,50 @@ func Test_httpFetcher_FetchIfModified(t *testing.T) {
 	}
 }
 
+func Test_httpFetcher_snapshot(t *testing.T) {
+
+	t.Run("when success to get workspace then save snapshot", func(t *testing.T) {
+		body, _ := ioutil.ReadFile("../../../testdata/workspace_config.json")
+		snapshot := &mockSnapshot{}
+		sut := &httpFetcher{
+			url: "localhost",
+			httpClient: &mockHttpClient{
+				res: &nethttp.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewReader(body)),
+				},
+			},
+			snapshot: snapshot,
+		}
+
+		_, ok, err := sut.FetchIfModified()
+
+		assert.Nil(t, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, [][]byte{body}, snapshot.saved)
+	})
+
+	t.Run("when workspace not modified then do not save snapshot", func(t *testing.T) {
+		snapshot := &mockSnapshot{}
+		sut := &httpFetcher{
+			url: "localhost",
+			httpClient: &mockHttpClient{
+				res: &nethttp.Response{
+					StatusCode: 304,
+					Body:       ioutil.NopCloser(bytes.NewReader(make([]byte, 0))),
+				},
+			},
+			snapshot: snapshot,
+		}
+
+		_, ok, err := sut.FetchIfModified()
+
+		assert.Nil(t, err)
+		assert.Equal(t, false, ok)
+		assert.Equal(t, 0, len(snapshot.saved))
+	})
+}
+
 type mockHttpClient struct {
 	req *nethttp.Request
 	res *nethttp.Response
@@ -2,7 +2,9 @@ package workspace
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"io"
 	"io/ioutil"
 )
 
@@ -30,3 +32,48 @@ func (f *FileFetcher) Fetch() (Workspace, bool) {
 }
 
 func (f *FileFetcher) Close() {}
+
+type StaticFetcher struct {
+	workspace Workspace
+	ready     chan struct{}
+}
+
+func NewStaticFetcher(workspace Workspace) *StaticFetcher {
+	ready := make(chan struct{})
+	if workspace != nil {
+		close(ready)
+	}
+	return &StaticFetcher{workspace: workspace, ready: ready}
+}
+
+func (f *StaticFetcher) Fetch() (Workspace, bool) {
+	if f.workspace != nil {
+		return f.workspace, true
+	} else {
+		return nil, false
+	}
+}
+
+func (f *StaticFetcher) Ready() <-chan struct{} {
+	return f.ready
+}
+
+func (f *StaticFetcher) Err() error {
+	if f.workspace == nil {
+		return errors.New("workspace not loaded")
+	}
+	return nil
+}
+
+func (f *StaticFetcher) AddUpdateListener(listener UpdateListener) {}
+
+func (f *StaticFetcher) Close() {}
+
+func Load(reader io.Reader) (Workspace, error) {
+	var dto WorkspaceDTO
+	err := json.NewDecoder(reader).Decode(&dto)
+	if err != nil {
+		return nil, err
+	}
+	return NewFrom(dto), nil
+}
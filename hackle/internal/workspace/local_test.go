@@ -2,6 +2,8 @@ package workspace
 
 import (
 	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -17,3 +19,43 @@ func TestFetch(t *testing.T) {
 	_, ok := fetcher2.Fetch()
 	assert.Equal(t, false, ok)
 }
+
+func TestStaticFetcher(t *testing.T) {
+	ws := NewFrom(WorkspaceDTO{})
+
+	fetcher1 := NewStaticFetcher(ws)
+	defer fetcher1.Close()
+	actual, ok := fetcher1.Fetch()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, ws, actual)
+	assert.Nil(t, fetcher1.Err())
+	select {
+	case <-fetcher1.Ready():
+	default:
+		assert.Fail(t, "expected ready")
+	}
+
+	fetcher2 := NewStaticFetcher(nil)
+	_, ok = fetcher2.Fetch()
+	assert.Equal(t, false, ok)
+	assert.NotNil(t, fetcher2.Err())
+	select {
+	case <-fetcher2.Ready():
+		assert.Fail(t, "expected not ready")
+	default:
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file, err := os.Open("../../../testdata/workspace_config.json")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	ws, err := Load(file)
+	assert.Nil(t, err)
+	_, ok := ws.GetExperiment(5)
+	assert.Equal(t, true, ok)
+
+	_, err = Load(strings.NewReader("{"))
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,10 @@
 package workspace
 
 import (
+	"errors"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
 	"sync"
@@ -9,20 +13,42 @@ import (
 
 type PollingFetcher struct {
 	httpFetcher      HttpFetcher
+	snapshot         Snapshot
 	pollingInterval  time.Duration
+	initialDelay     time.Duration
 	scheduler        schedule.Scheduler
+	backoff          backoff.Backoff
+	clock            clock.Clock
+	nextPollMillis   int64
 	currentWorkspace Workspace
 	pollingJob       schedule.Job
+	ready            chan struct{}
+	lastErr          error
+	suspended        bool
+	listeners        []UpdateListener
 	mu               sync.Mutex
 }
 
-func NewPollingFetcher(httpFetcher HttpFetcher, pollingInterval time.Duration, scheduler schedule.Scheduler) *PollingFetcher {
+func NewPollingFetcher(
+	httpFetcher HttpFetcher,
+	snapshot Snapshot,
+	pollingInterval time.Duration,
+	initialDelay time.Duration,
+	scheduler schedule.Scheduler,
+	backoff backoff.Backoff,
+	clock clock.Clock,
+) *PollingFetcher {
 	return &PollingFetcher{
 		httpFetcher:      httpFetcher,
+		snapshot:         snapshot,
 		pollingInterval:  pollingInterval,
+		initialDelay:     initialDelay,
 		scheduler:        scheduler,
+		backoff:          backoff,
+		clock:            clock,
 		currentWorkspace: nil,
 		pollingJob:       nil,
+		ready:            make(chan struct{}),
 	}
 }
 
@@ -36,10 +62,27 @@ func (f *PollingFetcher) Fetch() (Workspace, bool) {
 	}
 }
 
+func (f *PollingFetcher) Ready() <-chan struct{} {
+	return f.ready
+}
+
+func (f *PollingFetcher) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastErr
+}
+
+func (f *PollingFetcher) AddUpdateListener(listener UpdateListener) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, listener)
+}
+
 func (f *PollingFetcher) Start() {
 	if f.pollingJob == nil {
+		f.bootstrap()
 		f.poll()
-		f.pollingJob = f.scheduler.SchedulePeriodically(f.pollingInterval, f.poll)
+		f.pollingJob = f.scheduler.SchedulePeriodicallyWithDelay(f.initialDelay, f.pollingInterval, f.poll)
 	}
 }
 
@@ -49,15 +92,118 @@ func (f *PollingFetcher) Close() {
 	}
 }
 
+func (f *PollingFetcher) bootstrap() {
+	ws, ok := f.snapshot.Load()
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.currentWorkspace == nil {
+		f.setWorkspace(ws)
+		logger.Info("Workspace bootstrapped from snapshot.")
+	}
+}
+
 func (f *PollingFetcher) poll() {
+	if f.isSuspended() || f.isBackingOff() {
+		return
+	}
 	ws, ok, err := f.httpFetcher.FetchIfModified()
+	f.setLastErr(err)
 	if err != nil {
-		logger.Error("Failed to poll workspace: %v", err)
+		delay := f.backOff(err)
+		logger.Error("Failed to poll workspace. Next poll in %s: %v", delay, err)
 		return
 	}
+	f.resetBackoff()
 	if ok {
-		f.mu.Lock()
-		defer f.mu.Unlock()
-		f.currentWorkspace = ws
+		f.swap(ws, true)
+	}
+}
+
+func (f *PollingFetcher) update(ws Workspace) {
+	f.swap(ws, false)
+}
+
+// swap discards a polled workspace if streaming was connected while the poll was in flight,
+// so that it does not overwrite a newer streamed workspace.
+func (f *PollingFetcher) swap(ws Workspace, polled bool) {
+	f.mu.Lock()
+	if polled && f.suspended {
+		f.mu.Unlock()
+		return
+	}
+	oldWorkspace := f.currentWorkspace
+	f.lastErr = nil
+	f.setWorkspace(ws)
+	listeners := f.listeners
+	f.mu.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+	change := Diff(oldWorkspace, ws)
+	for _, listener := range listeners {
+		f.notify(listener, oldWorkspace, ws, change)
+	}
+}
+
+func (f *PollingFetcher) notify(listener UpdateListener, oldWorkspace Workspace, newWorkspace Workspace, change Change) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in workspace update listener: %v", r)
+		}
+	}()
+	listener(oldWorkspace, newWorkspace, change)
+}
+
+func (f *PollingFetcher) backOff(err error) time.Duration {
+	delay := f.backoff.Next()
+	var responseErr *http.ResponseError
+	if errors.As(err, &responseErr) && responseErr.RetryAfter > delay {
+		delay = responseErr.RetryAfter
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextPollMillis = f.clock.CurrentMillis() + delay.Milliseconds()
+	return delay
+}
+
+func (f *PollingFetcher) resetBackoff() {
+	f.backoff.Reset()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextPollMillis = 0
+}
+
+func (f *PollingFetcher) isBackingOff() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.clock.CurrentMillis() < f.nextPollMillis
+}
+
+func (f *PollingFetcher) setLastErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastErr = err
+}
+
+func (f *PollingFetcher) suspend(suspended bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.suspended = suspended
+}
+
+func (f *PollingFetcher) isSuspended() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.suspended
+}
+
+func (f *PollingFetcher) setWorkspace(ws Workspace) {
+	if f.currentWorkspace == nil {
+		close(f.ready)
 	}
+	f.currentWorkspace = ws
 }
@@ -2,6 +2,9 @@ package workspace
 
 import (
 	"errors"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/mocks"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
 	"github.com/stretchr/testify/assert"
@@ -72,8 +75,12 @@ func TestPollingFetcher_Fetch(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			sut := NewPollingFetcher(
 				tt.given.httpFetcher,
+				NewNoopSnapshot(),
+				tt.given.pollingInterval,
 				tt.given.pollingInterval,
 				tt.given.scheduler,
+				backoff.NewExponential(0, 0, 1),
+				clock.System,
 			)
 			ws, ok := tt.when(sut)
 			tt.then(sut, ws, ok)
@@ -149,8 +156,12 @@ func TestPollingFetcher_poll(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			sut := NewPollingFetcher(
 				tt.given.httpFetcher,
+				NewNoopSnapshot(),
+				tt.given.pollingInterval,
 				tt.given.pollingInterval,
 				tt.given.scheduler,
+				backoff.NewExponential(0, 0, 1),
+				clock.System,
 			)
 			ws, ok := tt.when(sut)
 			tt.then(sut, ws, ok)
@@ -248,8 +259,12 @@ func TestPollingFetcher_Start(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			sut := NewPollingFetcher(
 				tt.given.httpFetcher,
+				NewNoopSnapshot(),
+				tt.given.pollingInterval,
 				tt.given.pollingInterval,
 				tt.given.scheduler,
+				backoff.NewExponential(0, 0, 1),
+				clock.System,
 			)
 			ws, ok := tt.when(sut)
 			tt.then(sut, tt.given, ws, ok)
@@ -285,3 +300,277 @@ func (m *mockHttpFetcher) Count() int {
 	defer m.mu.Unlock()
 	return m.count
 }
+
+func TestPollingFetcher_bootstrap(t *testing.T) {
+
+	t.Run("when failed to poll then return snapshot workspace", func(t *testing.T) {
+		snapshot := &mockSnapshot{workspace: snapshotWorkspace{mocks.CreateWorkspace()}}
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{errors.New("fail")}},
+			snapshot,
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+		sut.Start()
+		defer sut.Close()
+
+		ws, ok := sut.Fetch()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, IsSnapshot(ws))
+	})
+
+	t.Run("when success to poll then replace snapshot workspace", func(t *testing.T) {
+		snapshot := &mockSnapshot{workspace: snapshotWorkspace{mocks.CreateWorkspace()}}
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{mocks.CreateWorkspace()}},
+			snapshot,
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+		sut.Start()
+		defer sut.Close()
+
+		ws, ok := sut.Fetch()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, false, IsSnapshot(ws))
+	})
+}
+
+func TestPollingFetcher_backoff(t *testing.T) {
+
+	t.Run("when failed to poll then skip polls until backoff elapsed", func(t *testing.T) {
+		httpFetcher := &mockHttpFetcher{returns: []interface{}{errors.New("fail"), errors.New("fail"), mocks.CreateWorkspace()}}
+		currentMillis := &mutableClock{millis: 1000}
+		sut := NewPollingFetcher(
+			httpFetcher,
+			NewNoopSnapshot(),
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(10*time.Second, time.Minute, 2),
+			currentMillis,
+		)
+
+		sut.poll()
+		assert.Equal(t, 1, httpFetcher.Count())
+
+		currentMillis.millis = 10999
+		sut.poll()
+		assert.Equal(t, 1, httpFetcher.Count())
+
+		currentMillis.millis = 11000
+		sut.poll()
+		assert.Equal(t, 2, httpFetcher.Count())
+
+		currentMillis.millis = 30999
+		sut.poll()
+		assert.Equal(t, 2, httpFetcher.Count())
+
+		currentMillis.millis = 31000
+		sut.poll()
+		assert.Equal(t, 3, httpFetcher.Count())
+		_, ok := sut.Fetch()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, false, sut.isBackingOff())
+	})
+
+	t.Run("when Retry-After is longer than backoff then wait Retry-After", func(t *testing.T) {
+		responseErr := &http.ResponseError{StatusCode: 429, RetryAfter: 60 * time.Second}
+		httpFetcher := &mockHttpFetcher{returns: []interface{}{responseErr, mocks.CreateWorkspace()}}
+		currentMillis := &mutableClock{millis: 0}
+		sut := NewPollingFetcher(
+			httpFetcher,
+			NewNoopSnapshot(),
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(10*time.Second, time.Minute, 2),
+			currentMillis,
+		)
+
+		sut.poll()
+		currentMillis.millis = 59999
+		sut.poll()
+		assert.Equal(t, 1, httpFetcher.Count())
+
+		currentMillis.millis = 60000
+		sut.poll()
+		assert.Equal(t, 2, httpFetcher.Count())
+	})
+}
+
+type mutableClock struct {
+	millis int64
+}
+
+func (c *mutableClock) CurrentMillis() int64 {
+	return c.millis
+}
+
+func (c *mutableClock) Tick() int64 {
+	return c.millis
+}
+
+type mockSnapshot struct {
+	workspace Workspace
+	saved     [][]byte
+}
+
+func (m *mockSnapshot) Load() (Workspace, bool) {
+	if m.workspace == nil {
+		return nil, false
+	}
+	return m.workspace, true
+}
+
+func (m *mockSnapshot) Save(data []byte) error {
+	m.saved = append(m.saved, data)
+	return nil
+}
+
+func TestPollingFetcher_Ready(t *testing.T) {
+
+	t.Run("when failed to poll then not ready", func(t *testing.T) {
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{errors.New("fail")}},
+			NewNoopSnapshot(),
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+		sut.Start()
+		defer sut.Close()
+
+		select {
+		case <-sut.Ready():
+			assert.Fail(t, "expected not ready")
+		default:
+		}
+		assert.Equal(t, errors.New("fail"), sut.Err())
+	})
+
+	t.Run("when workspace is fetched then ready", func(t *testing.T) {
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{errors.New("fail"), mocks.CreateWorkspace(), nil, nil, nil, nil}},
+			NewNoopSnapshot(),
+			100*time.Millisecond,
+			100*time.Millisecond,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+		sut.Start()
+		defer sut.Close()
+
+		select {
+		case <-sut.Ready():
+		case <-time.After(time.Second):
+			assert.Fail(t, "expected ready")
+		}
+		assert.Nil(t, sut.Err())
+	})
+
+	t.Run("when bootstrapped from snapshot then ready", func(t *testing.T) {
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{errors.New("fail")}},
+			&mockSnapshot{workspace: snapshotWorkspace{mocks.CreateWorkspace()}},
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+		sut.Start()
+		defer sut.Close()
+
+		select {
+		case <-sut.Ready():
+		default:
+			assert.Fail(t, "expected ready")
+		}
+	})
+}
+
+func TestPollingFetcher_AddUpdateListener(t *testing.T) {
+
+	t.Run("when workspace updated then notify listeners", func(t *testing.T) {
+		ws1 := mocks.CreateWorkspace()
+		ws2 := mocks.CreateWorkspace()
+		sut := NewPollingFetcher(
+			&mockHttpFetcher{returns: []interface{}{ws1, nil, ws2, nil, nil, nil, nil, nil}},
+			NewNoopSnapshot(),
+			100*time.Millisecond,
+			100*time.Millisecond,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+
+		var updates [][]Workspace
+		var changes []Change
+		var mu sync.Mutex
+		sut.AddUpdateListener(func(oldWorkspace Workspace, newWorkspace Workspace, change Change) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, []Workspace{oldWorkspace, newWorkspace})
+			changes = append(changes, change)
+		})
+		sut.AddUpdateListener(func(oldWorkspace Workspace, newWorkspace Workspace, change Change) {
+			panic("listener panic")
+		})
+		sut.Start()
+		time.Sleep(250 * time.Millisecond)
+		sut.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 2, len(updates))
+		assert.Nil(t, updates[0][0])
+		assert.Same(t, ws1, updates[0][1])
+		assert.Same(t, ws1, updates[1][0])
+		assert.Same(t, ws2, updates[1][1])
+		assert.Equal(t, Diff(nil, ws1), changes[0])
+		assert.Equal(t, Diff(ws1, ws2), changes[1])
+	})
+}
+
+type httpFetcherFunc func() (Workspace, bool, error)
+
+func (f httpFetcherFunc) FetchIfModified() (Workspace, bool, error) {
+	return f()
+}
+
+func TestPollingFetcher_suspend(t *testing.T) {
+
+	t.Run("when suspended while polling then discard polled workspace", func(t *testing.T) {
+		streamed := mocks.CreateWorkspace()
+		var sut *PollingFetcher
+		sut = NewPollingFetcher(
+			httpFetcherFunc(func() (Workspace, bool, error) {
+				sut.suspend(true)
+				sut.update(streamed)
+				return mocks.CreateWorkspace(), true, nil
+			}),
+			NewNoopSnapshot(),
+			10*time.Second,
+			10*time.Second,
+			schedule.NewTickerScheduler(),
+			backoff.NewExponential(0, 0, 1),
+			clock.System,
+		)
+
+		sut.poll()
+
+		ws, ok := sut.Fetch()
+		assert.Equal(t, true, ok)
+		assert.Same(t, streamed, ws)
+	})
+}
@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type Snapshot interface {
+	Load() (Workspace, bool)
+	Save(data []byte) error
+}
+
+func NewFileSnapshot(filename string) Snapshot {
+	return &fileSnapshot{filename: filename}
+}
+
+type fileSnapshot struct {
+	filename string
+}
+
+func (s *fileSnapshot) Load() (Workspace, bool) {
+	file, err := os.Open(s.filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("failed to open workspace snapshot: %v", err)
+		}
+		return nil, false
+	}
+	defer func() {
+		e := file.Close()
+		if e != nil {
+			logger.Warn("failed to close workspace snapshot: %v", e)
+		}
+	}()
+
+	ws, err := Load(file)
+	if err != nil {
+		logger.Warn("failed to load workspace snapshot: %v", err)
+		return nil, false
+	}
+	return snapshotWorkspace{ws}, true
+}
+
+func (s *fileSnapshot) Save(data []byte) error {
+	dir := filepath.Dir(s.filename)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(s.filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.filename)
+}
+
+func NewNoopSnapshot() Snapshot {
+	return &noopSnapshot{}
+}
+
+type noopSnapshot struct{}
+
+func (s *noopSnapshot) Load() (Workspace, bool) {
+	return nil, false
+}
+
+func (s *noopSnapshot) Save(data []byte) error {
+	return nil
+}
+
+type snapshotWorkspace struct {
+	Workspace
+}
+
+func IsSnapshot(ws Workspace) bool {
+	_, ok := ws.(snapshotWorkspace)
+	return ok
+}
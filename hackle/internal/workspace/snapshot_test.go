@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSnapshot(t *testing.T) {
+
+	t.Run("when snapshot file not exist then return false", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		sut := NewFileSnapshot(filepath.Join(dir, "workspace.json"))
+		_, ok := sut.Load()
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("when snapshot file is invalid then return false", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		filename := filepath.Join(dir, "workspace.json")
+		_ = ioutil.WriteFile(filename, []byte("{"), 0644)
+
+		sut := NewFileSnapshot(filename)
+		_, ok := sut.Load()
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		dir, _ := ioutil.TempDir("", "hackle")
+		defer os.RemoveAll(dir)
+
+		data, _ := ioutil.ReadFile("../../../testdata/workspace_config.json")
+		sut := NewFileSnapshot(filepath.Join(dir, "snapshot", "workspace.json"))
+
+		err := sut.Save(data)
+		assert.Nil(t, err)
+
+		ws, ok := sut.Load()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, IsSnapshot(ws))
+		_, ok = ws.GetExperiment(5)
+		assert.Equal(t, true, ok)
+
+		files, _ := ioutil.ReadDir(filepath.Join(dir, "snapshot"))
+		assert.Equal(t, 1, len(files))
+	})
+}
+
+func TestNoopSnapshot(t *testing.T) {
+	sut := NewNoopSnapshot()
+	assert.Nil(t, sut.Save([]byte("{}")))
+	_, ok := sut.Load()
+	assert.Equal(t, false, ok)
+}
+
+func TestIsSnapshot(t *testing.T) {
+	ws := NewFrom(WorkspaceDTO{})
+	assert.Equal(t, false, IsSnapshot(ws))
+	assert.Equal(t, true, IsSnapshot(snapshotWorkspace{ws}))
+}
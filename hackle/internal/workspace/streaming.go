@@ -0,0 +1,211 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxStreamEventSize = 16 * 1024 * 1024
+
+type StreamingFetcher struct {
+	url            string
+	httpClient     http.Client
+	pollingFetcher *PollingFetcher
+	backoff        backoff.Backoff
+	idleTimeout    time.Duration
+	ctx            context.Context
+	cancel         context.CancelFunc
+	streamingWait  *sync.WaitGroup
+	isStarted      bool
+	mu             sync.Mutex
+}
+
+func NewStreamingFetcher(
+	sdkUrl string,
+	sdk model.Sdk,
+	httpClient http.Client,
+	pollingFetcher *PollingFetcher,
+	backoff backoff.Backoff,
+	idleTimeout time.Duration,
+) *StreamingFetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StreamingFetcher{
+		url:            sdkUrl + "/api/v2/workspaces/" + sdk.Key + "/config/stream",
+		httpClient:     httpClient,
+		pollingFetcher: pollingFetcher,
+		backoff:        backoff,
+		idleTimeout:    idleTimeout,
+		ctx:            ctx,
+		cancel:         cancel,
+		streamingWait:  &sync.WaitGroup{},
+		isStarted:      false,
+	}
+}
+
+func (f *StreamingFetcher) Fetch() (Workspace, bool) {
+	return f.pollingFetcher.Fetch()
+}
+
+func (f *StreamingFetcher) Ready() <-chan struct{} {
+	return f.pollingFetcher.Ready()
+}
+
+func (f *StreamingFetcher) Err() error {
+	return f.pollingFetcher.Err()
+}
+
+func (f *StreamingFetcher) AddUpdateListener(listener UpdateListener) {
+	f.pollingFetcher.AddUpdateListener(listener)
+}
+
+func (f *StreamingFetcher) Start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.isStarted {
+		return
+	}
+	f.pollingFetcher.Start()
+	f.streamingWait.Add(1)
+	go f.streaming()
+	f.isStarted = true
+}
+
+func (f *StreamingFetcher) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancel()
+	f.streamingWait.Wait()
+	f.pollingFetcher.Close()
+}
+
+func (f *StreamingFetcher) streaming() {
+	defer f.streamingWait.Done()
+	for {
+		err := f.stream()
+		f.pollingFetcher.suspend(false)
+		if f.ctx.Err() != nil {
+			return
+		}
+
+		delay := f.backoff.Next()
+		logger.Warn("Workspace stream disconnected. Falling back to polling and reconnecting in %s: %v", delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-f.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// stream is cancelled if nothing is received for idleTimeout, so that a half-open
+// connection falls back to polling instead of blocking forever.
+func (f *StreamingFetcher) stream() error {
+	req, err := nethttp.NewRequest(nethttp.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+	idle := time.AfterFunc(f.idleTimeout, cancel)
+	defer idle.Stop()
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	res, err := f.httpClient.Execute(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		e := res.Body.Close()
+		if e != nil {
+			logger.Warn("failed to close response body: %v", e)
+		}
+	}()
+
+	if !http.IsSuccessful(res) {
+		return fmt.Errorf("http status code: %d", res.StatusCode)
+	}
+
+	f.backoff.Reset()
+	f.pollingFetcher.suspend(true)
+	logger.Info("Workspace stream connected.")
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamEventSize)
+
+	var eventType string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		idle.Reset(f.idleTimeout)
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				f.handleEvent(eventType, data.Bytes())
+			}
+			eventType = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			continue
+		default:
+			field, value := parseField(line)
+			switch field {
+			case "event":
+				eventType = value
+			case "data":
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+		}
+	}
+
+	if f.ctx.Err() == nil && ctx.Err() != nil {
+		return fmt.Errorf("no data received for %s", f.idleTimeout)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("stream closed")
+}
+
+func (f *StreamingFetcher) handleEvent(eventType string, data []byte) {
+	if eventType != "" && eventType != "workspace" {
+		return
+	}
+	ws, err := Load(bytes.NewReader(data))
+	if err != nil {
+		logger.Warn("failed to load streamed workspace: %v", err)
+		return
+	}
+	f.pollingFetcher.update(ws)
+
+	err = f.pollingFetcher.snapshot.Save(data)
+	if err != nil {
+		logger.Warn("failed to save workspace snapshot: %v", err)
+	}
+}
+
+func parseField(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimPrefix(line[i+1:], " ")
+}
@@ -0,0 +1,212 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/backoff"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/clock"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/http"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/mocks"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewStreamingFetcher(t *testing.T) {
+	sut := NewStreamingFetcher("localhost", model.Sdk{Key: "sdk_key"}, &mockHttpClient{}, nil, backoff.NewExponential(time.Second, time.Second, 2), time.Minute)
+	assert.Equal(t, "localhost/api/v2/workspaces/sdk_key/config/stream", sut.url)
+}
+
+func TestStreamingFetcher(t *testing.T) {
+
+	workspaceJson, _ := ioutil.ReadFile("../../../testdata/workspace_config.json")
+	workspaceData := strings.ReplaceAll(string(workspaceJson), "\n", "")
+
+	t.Run("apply pushed workspace", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			assert.Equal(t, "sdk_key", r.Header.Get("X-HACKLE-SDK-KEY"))
+			writeEvent(w, ": comment\n")
+			writeEvent(w, "event: ping\ndata: {}\n\n")
+			writeEvent(w, fmt.Sprintf("event: workspace\ndata: %s\n\n", workspaceData))
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, _ := streamingFetcher(server.URL, []interface{}{errors.New("fail")})
+		sut.Start()
+		defer sut.Close()
+
+		assertEventually(t, func() bool {
+			ws, ok := sut.Fetch()
+			if !ok {
+				return false
+			}
+			_, ok = ws.GetExperiment(5)
+			return ok
+		})
+		assert.Nil(t, sut.Err())
+	})
+
+	t.Run("multi line data", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			writeEvent(w, "data: {\"experiments\": [],\ndata: \"events\": [{\"id\": 1, \"key\": \"purchase\"}]}\n\n")
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, _ := streamingFetcher(server.URL, []interface{}{errors.New("fail")})
+		sut.Start()
+		defer sut.Close()
+
+		assertEventually(t, func() bool {
+			ws, ok := sut.Fetch()
+			if !ok {
+				return false
+			}
+			_, ok = ws.GetEventType("purchase")
+			return ok
+		})
+	})
+
+	t.Run("when stream connected then suspend polling", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			writeEvent(w, fmt.Sprintf("data: %s\n\n", workspaceData))
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, httpFetcher := streamingFetcher(server.URL, []interface{}{nil})
+		sut.Start()
+		defer sut.Close()
+
+		time.Sleep(350 * time.Millisecond)
+		assert.Equal(t, 1, httpFetcher.Count())
+	})
+
+	t.Run("when stream disconnected then fall back to polling and reconnect", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			if connection == 1 {
+				nethttp.Error(w, "unavailable", nethttp.StatusServiceUnavailable)
+				return
+			}
+			writeEvent(w, fmt.Sprintf("data: %s\n\n", workspaceData))
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, httpFetcher := streamingFetcher(server.URL, []interface{}{nil, mocks.CreateWorkspace()})
+		sut.backoff = backoff.NewExponential(250*time.Millisecond, time.Second, 2)
+		sut.Start()
+		defer sut.Close()
+
+		assertEventually(t, func() bool {
+			return httpFetcher.Count() >= 2
+		})
+		assertEventually(t, func() bool {
+			ws, ok := sut.Fetch()
+			if !ok {
+				return false
+			}
+			_, ok = ws.GetExperiment(5)
+			return ok
+		})
+		assert.Equal(t, 2, server.Connections())
+	})
+
+	t.Run("when no data received until idle timeout then fall back to polling and reconnect", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			writeEvent(w, fmt.Sprintf("data: %s\n\n", workspaceData))
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, httpFetcher := streamingFetcher(server.URL, []interface{}{nil})
+		sut.idleTimeout = 250 * time.Millisecond
+		sut.backoff = backoff.NewExponential(250*time.Millisecond, time.Second, 2)
+		sut.Start()
+		defer sut.Close()
+
+		assertEventually(t, func() bool {
+			return httpFetcher.Count() >= 2
+		})
+		assertEventually(t, func() bool {
+			return server.Connections() >= 2
+		})
+	})
+
+	t.Run("close", func(t *testing.T) {
+		server := newSseServer(func(w nethttp.ResponseWriter, r *nethttp.Request, connection int) {
+			<-r.Context().Done()
+		})
+		defer server.Close()
+
+		sut, _ := streamingFetcher(server.URL, []interface{}{nil})
+		sut.Start()
+		time.Sleep(50 * time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			sut.Close()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "expected closed")
+		}
+	})
+}
+
+func streamingFetcher(url string, polls []interface{}) (*StreamingFetcher, *mockHttpFetcher) {
+	for len(polls) < 100 {
+		polls = append(polls, nil)
+	}
+	httpFetcher := &mockHttpFetcher{returns: polls}
+	pollingFetcher := NewPollingFetcher(httpFetcher, NewNoopSnapshot(), 100*time.Millisecond, 100*time.Millisecond, schedule.NewTickerScheduler(), backoff.NewExponential(0, 0, 1), clock.System)
+	httpClient := http.NewClient(model.Sdk{Key: "sdk_key"}, clock.System, &nethttp.Client{})
+	sut := NewStreamingFetcher(url, model.Sdk{Key: "sdk_key"}, httpClient, pollingFetcher, backoff.NewExponential(10*time.Millisecond, 100*time.Millisecond, 2), time.Minute)
+	sut.url = url
+	return sut, httpFetcher
+}
+
+type sseServer struct {
+	*httptest.Server
+	connections int
+	mu          sync.Mutex
+}
+
+func newSseServer(handler func(w nethttp.ResponseWriter, r *nethttp.Request, connection int)) *sseServer {
+	s := &sseServer{}
+	s.Server = httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		s.mu.Lock()
+		s.connections++
+		connection := s.connections
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		handler(w, r, connection)
+	}))
+	return s
+}
+
+func (s *sseServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func writeEvent(w nethttp.ResponseWriter, event string) {
+	_, _ = w.Write([]byte(event))
+	w.(nethttp.Flusher).Flush()
+}
+
+func assertEventually(t *testing.T, condition func() bool) {
+	assert.Eventually(t, condition, 2*time.Second, 10*time.Millisecond)
+}
@@ -3,6 +3,7 @@ package workspace
 import (
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
+	"sort"
 )
 
 type Workspace interface {
@@ -14,6 +15,10 @@ type Workspace interface {
 	GetContainer(containerID int64) (model.Container, bool)
 	GetParameterConfiguration(parameterConfigurationID int64) (model.ParameterConfiguration, bool)
 	GetRemoteConfigParameter(parameterKey string) (model.RemoteConfigParameter, bool)
+	GetExperiments() []model.Experiment
+	GetFeatureFlags() []model.Experiment
+	GetSegments() []model.Segment
+	GetRemoteConfigParameters() []model.RemoteConfigParameter
 }
 
 type workspace struct {
@@ -192,6 +197,42 @@ func (w *workspace) GetRemoteConfigParameter(parameterKey string) (model.RemoteC
 	return remoteConfigParameter, ok
 }
 
+func (w *workspace) GetExperiments() []model.Experiment {
+	experiments := make([]model.Experiment, 0, len(w.experiments))
+	for _, it := range w.experiments {
+		experiments = append(experiments, it)
+	}
+	sort.Slice(experiments, func(i, j int) bool { return experiments[i].Key < experiments[j].Key })
+	return experiments
+}
+
+func (w *workspace) GetFeatureFlags() []model.Experiment {
+	featureFlags := make([]model.Experiment, 0, len(w.featureFlags))
+	for _, it := range w.featureFlags {
+		featureFlags = append(featureFlags, it)
+	}
+	sort.Slice(featureFlags, func(i, j int) bool { return featureFlags[i].Key < featureFlags[j].Key })
+	return featureFlags
+}
+
+func (w *workspace) GetSegments() []model.Segment {
+	segments := make([]model.Segment, 0, len(w.segments))
+	for _, it := range w.segments {
+		segments = append(segments, it)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Key < segments[j].Key })
+	return segments
+}
+
+func (w *workspace) GetRemoteConfigParameters() []model.RemoteConfigParameter {
+	parameters := make([]model.RemoteConfigParameter, 0, len(w.remoteConfigParameters))
+	for _, it := range w.remoteConfigParameters {
+		parameters = append(parameters, it)
+	}
+	sort.Slice(parameters, func(i, j int) bool { return parameters[i].Key < parameters[j].Key })
+	return parameters
+}
+
 func newExperiment(dto ExperimentDTO, experimentType model.ExperimentType) (model.Experiment, bool) {
 	execution := dto.Execution
 	status, ok := model.NewExperimentStatusFrom(execution.Status)
@@ -602,6 +602,26 @@ func TestWorkspace(t *testing.T) {
 	}, r1)
 }
 
+func TestWorkspace_list(t *testing.T) {
+	w, _ := NewFileFetcher("../../../testdata/workspace_config.json").Fetch()
+
+	var experimentKeys []int64
+	for _, it := range w.GetExperiments() {
+		experimentKeys = append(experimentKeys, it.Key)
+	}
+	assert.Equal(t, []int64{5, 6, 7, 8, 9, 10, 11}, experimentKeys)
+
+	var featureKeys []int64
+	for _, it := range w.GetFeatureFlags() {
+		featureKeys = append(featureKeys, it.Key)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4}, featureKeys)
+
+	assert.Equal(t, 1, len(w.GetRemoteConfigParameters()))
+	assert.Equal(t, "json_key_1", w.GetRemoteConfigParameters()[0].Key)
+	assert.Equal(t, len(w.(*workspace).segments), len(w.GetSegments()))
+}
+
 func TestWorkspace_Invalid(t *testing.T) {
 	w, _ := NewFileFetcher("../../../testdata/workspace_invalid_config.json").Fetch()
 
@@ -0,0 +1,77 @@
+package hackle
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/evaluation/evaluator/experiment"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/model"
+	"io/ioutil"
+	"os"
+	"strconv"
+)
+
+// localOverridesDTO is the format of local override files and environment variables.
+//
+//	{
+//	  "experiments": {"42": {"user-1": "B"}},
+//	  "featureFlags": {"7": {"user-1": true}}
+//	}
+type localOverridesDTO struct {
+	Experiments  map[string]map[string]string `json:"experiments"`
+	FeatureFlags map[string]map[string]bool   `json:"featureFlags"`
+}
+
+func (c *Config) newLocalOverrideStore() *experiment.LocalOverrideStore {
+	store := experiment.NewLocalOverrideStore()
+	if c.localOverrideFile != "" {
+		data, err := ioutil.ReadFile(c.localOverrideFile)
+		if err == nil {
+			err = loadLocalOverrides(store, data)
+		}
+		if err != nil {
+			logger.Error("Failed to load local overrides from file [%s]: %v", c.localOverrideFile, err)
+		}
+	}
+	if c.localOverrideEnv != "" {
+		if value, ok := os.LookupEnv(c.localOverrideEnv); ok {
+			if err := loadLocalOverrides(store, []byte(value)); err != nil {
+				logger.Error("Failed to load local overrides from environment variable [%s]: %v", c.localOverrideEnv, err)
+			}
+		}
+	}
+	return store
+}
+
+func loadLocalOverrides(store *experiment.LocalOverrideStore, data []byte) error {
+	var dto localOverridesDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+	for key, overrides := range dto.Experiments {
+		experimentKey, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid experiment key [%s]", key)
+		}
+		for identifier, variationKey := range overrides {
+			store.Set(model.ExperimentTypeAbTest, experimentKey, identifier, variationKey)
+		}
+	}
+	for key, overrides := range dto.FeatureFlags {
+		featureKey, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid feature key [%s]", key)
+		}
+		for identifier, isOn := range overrides {
+			store.Set(model.ExperimentTypeFeatureFlag, featureKey, identifier, featureFlagVariationKey(isOn))
+		}
+	}
+	return nil
+}
+
+func featureFlagVariationKey(isOn bool) string {
+	if isOn {
+		return "B"
+	}
+	return "A"
+}
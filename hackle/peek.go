@@ -0,0 +1,38 @@
+package hackle
+
+import "context"
+
+// PeekClient decides like Client but never emits exposure events.
+// Use Client.LogExposure to record the exposure when the user actually sees the variation.
+type PeekClient interface {
+	Variation(experimentKey int64, user User) string
+	VariationDetail(experimentKey int64, user User) ExperimentDecision
+	IsFeatureOn(featureKey int64, user User) bool
+	FeatureFlagDetail(featureKey int64, user User) FeatureFlagDecision
+	RemoteConfig(user User) RemoteConfig
+}
+
+type peekClient struct {
+	client *client
+}
+
+func (p *peekClient) Variation(experimentKey int64, user User) string {
+	return p.VariationDetail(experimentKey, user).Variation()
+}
+
+func (p *peekClient) VariationDetail(experimentKey int64, user User) ExperimentDecision {
+	return p.client.variationDetail(context.Background(), experimentKey, user, false)
+}
+
+func (p *peekClient) IsFeatureOn(featureKey int64, user User) bool {
+	return p.FeatureFlagDetail(featureKey, user).IsOn()
+}
+
+func (p *peekClient) FeatureFlagDetail(featureKey int64, user User) FeatureFlagDecision {
+	return p.client.featureFlagDetail(context.Background(), featureKey, user, false)
+}
+
+func (p *peekClient) RemoteConfig(user User) RemoteConfig {
+	c := p.client
+	return newRemoteConfig(context.Background(), user, c.userResolver, c.core, c.watcher, false)
+}
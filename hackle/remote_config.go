@@ -1,31 +1,41 @@
 package hackle
 
 import (
+	"context"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/types"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
+	"reflect"
+	"sync"
 )
 
 type RemoteConfig interface {
 	GetString(key string, defaultValue string) string
 	GetNumber(key string, defaultValue float64) float64
 	GetBool(key string, defaultValue bool) bool
+	Watch(key string, defaultValue interface{}, listener func(oldDecision RemoteConfigDecision, newDecision RemoteConfigDecision)) (cancel func())
 }
 
-func newRemoteConfig(user User, userResolve user.Resolver, core core.Core) RemoteConfig {
+func newRemoteConfig(ctx context.Context, user User, userResolve user.Resolver, core core.Core, watcher *watcher, exposure bool) RemoteConfig {
 	return &remoteConfig{
+		ctx:          ctx,
 		user:         user,
 		userResolver: userResolve,
 		core:         core,
+		watcher:      watcher,
+		exposure:     exposure,
 	}
 }
 
 type remoteConfig struct {
+	ctx          context.Context
 	user         User
 	userResolver user.Resolver
 	core         core.Core
+	watcher      *watcher
+	exposure     bool
 }
 
 func (c *remoteConfig) GetString(key string, defaultValue string) string {
@@ -55,14 +65,50 @@ func (c *remoteConfig) GetBool(key string, defaultValue bool) bool {
 	}
 }
 
+// Watch re-evaluates the parameter without exposures, since the user has not seen anything.
+func (c *remoteConfig) Watch(key string, defaultValue interface{}, listener func(oldDecision RemoteConfigDecision, newDecision RemoteConfigDecision)) (cancel func()) {
+	valueType, ok := valueTypeOf(defaultValue)
+	if !ok {
+		logger.Error("Unsupported default value type [%T] for remote config parameter[%s]. Not watching.", defaultValue, key)
+		return func() {}
+	}
+	peek := *c
+	peek.exposure = false
+	current := peek.get(key, defaultValue, valueType)
+	var mu sync.Mutex
+	return c.watcher.watch(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		d := peek.get(key, defaultValue, valueType)
+		if reflect.DeepEqual(current.Value(), d.Value()) {
+			return
+		}
+		old := current
+		current = d
+		listener(old, d)
+	})
+}
+
+func valueTypeOf(value interface{}) (types.ValueType, bool) {
+	switch value.(type) {
+	case string:
+		return types.String, true
+	case float64:
+		return types.Number, true
+	case bool:
+		return types.Bool, true
+	}
+	return "", false
+}
+
 func (c *remoteConfig) get(key string, defaultValue interface{}, valueType types.ValueType) RemoteConfigDecision {
-	hackleUser, ok := c.userResolver.Resolve(c.user)
+	hackleUser, ok := resolveUser(c.ctx, c.userResolver, c.user)
 	if !ok {
 		return decision.NewRemoteConfigDecision(defaultValue, decision.ReasonInvalidInput)
 	}
-	d, err := c.core.RemoteConfig(key, hackleUser, valueType, defaultValue)
+	d, err := c.core.RemoteConfig(c.ctx, key, hackleUser, valueType, defaultValue, c.exposure)
 	if err != nil {
-		logger.Error("Unexpected exception while deciding remote config parameter[%s]. Returning default value[%v]: %v", key, defaultValue, err)
+		logger.Error("Unexpected exception while deciding remote config parameter[%s]. Returning default value[%v]: %v", key, defaultValue, withContextErr(c.ctx, err))
 		return decision.NewRemoteConfigDecision(defaultValue, decision.ReasonException)
 	}
 	return d
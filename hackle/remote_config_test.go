@@ -1,6 +1,7 @@
 package hackle
 
 import (
+	"context"
 	"errors"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/core"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/decision"
@@ -10,7 +11,7 @@ import (
 )
 
 func Test_newRemoteConfig(t *testing.T) {
-	rc := newRemoteConfig(User{}, user.NewResolver(), &mockCore{})
+	rc := newRemoteConfig(context.Background(), User{}, user.NewResolver(), &mockCore{}, newWatcher(&mockNotifier{}), true)
 	assert.IsType(t, &remoteConfig{}, rc)
 }
 
@@ -257,3 +258,40 @@ func Test_remoteConfig_GetBool(t *testing.T) {
 		})
 	}
 }
+
+func Test_remoteConfig_Watch(t *testing.T) {
+
+	t.Run("when value changed then notify", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		core := &mockCore{remoteConfig: decision.NewRemoteConfigDecision("a", decision.ReasonDefaultRule)}
+		sut := newRemoteConfig(context.Background(), User{id: "42"}, user.NewResolver(), core, newWatcher(notifier), true)
+
+		var changes [][]interface{}
+		cancel := sut.Watch("rc", "default", func(oldDecision RemoteConfigDecision, newDecision RemoteConfigDecision) {
+			changes = append(changes, []interface{}{oldDecision.Value(), newDecision.Value()})
+		})
+
+		notifier.update(nil, nil)
+		assert.Equal(t, 0, len(changes))
+
+		core.remoteConfig = decision.NewRemoteConfigDecision("b", decision.ReasonTargetRuleMatch)
+		notifier.update(nil, nil)
+		notifier.update(nil, nil)
+		assert.Equal(t, [][]interface{}{{"a", "b"}}, changes)
+
+		cancel()
+		core.remoteConfig = decision.NewRemoteConfigDecision("c", decision.ReasonTargetRuleMatch)
+		notifier.update(nil, nil)
+		assert.Equal(t, [][]interface{}{{"a", "b"}}, changes)
+	})
+
+	t.Run("when default value type is not supported then do not watch", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		sut := newRemoteConfig(context.Background(), User{id: "42"}, user.NewResolver(), &mockCore{}, newWatcher(notifier), true)
+
+		cancel := sut.Watch("rc", 42, func(oldDecision RemoteConfigDecision, newDecision RemoteConfigDecision) {})
+		cancel()
+
+		assert.Equal(t, 0, len(notifier.listeners))
+	})
+}
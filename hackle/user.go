@@ -1,8 +1,10 @@
 package hackle
 
 import (
+	"context"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/identifiers"
 	"github.com/hackle-io/hackle-go-sdk/hackle/internal/properties"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 )
 
 type User struct {
@@ -92,3 +94,32 @@ func (b *UserBuilder) Build() User {
 		properties:  b.properties.Build(),
 	}
 }
+
+type userContextKey struct{}
+
+// ContextWithUser returns a copy of ctx carrying user.
+// The Ctx variants of Client fall back to this user when called with an empty User.
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+func UserFromContext(ctx context.Context) (User, bool) {
+	if ctx == nil {
+		return User{}, false
+	}
+	u, ok := ctx.Value(userContextKey{}).(User)
+	return u, ok
+}
+
+func (u User) isEmpty() bool {
+	return u.id == "" && u.userID == "" && u.deviceID == "" && len(u.identifiers) == 0 && len(u.properties) == 0
+}
+
+func resolveUser(ctx context.Context, resolver user.Resolver, u User) (user.HackleUser, bool) {
+	if u.isEmpty() {
+		if contextUser, ok := UserFromContext(ctx); ok {
+			u = contextUser
+		}
+	}
+	return resolver.Resolve(u)
+}
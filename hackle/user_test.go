@@ -1,6 +1,8 @@
 package hackle
 
 import (
+	"context"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/user"
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"strings"
@@ -77,3 +79,35 @@ func TestUser(t *testing.T) {
 		assert.Equal(t, false, ok)
 	})
 }
+
+func TestUserContext(t *testing.T) {
+
+	t.Run("store and resolve user", func(t *testing.T) {
+		user := NewUserBuilder().ID("id").Build()
+		ctx := ContextWithUser(context.Background(), user)
+
+		resolved, ok := UserFromContext(ctx)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, user, resolved)
+	})
+
+	t.Run("when no user in context then not ok", func(t *testing.T) {
+		_, ok := UserFromContext(context.Background())
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("resolve user from context only when user is empty", func(t *testing.T) {
+		ctx := ContextWithUser(context.Background(), NewUserBuilder().ID("context").Build())
+
+		hackleUser, ok := resolveUser(ctx, user.NewResolver(), User{})
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "context", hackleUser.Identifiers["$id"])
+
+		hackleUser, ok = resolveUser(ctx, user.NewResolver(), NewUserBuilder().ID("explicit").Build())
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "explicit", hackleUser.Identifiers["$id"])
+
+		_, ok = resolveUser(context.Background(), user.NewResolver(), User{})
+		assert.Equal(t, false, ok)
+	})
+}
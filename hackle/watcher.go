@@ -0,0 +1,62 @@
+package hackle
+
+import (
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/logger"
+	"github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
+	"sync"
+)
+
+func newWatcher(notifier workspace.Notifier) *watcher {
+	return &watcher{
+		notifier: notifier,
+		watches:  make(map[int64]func()),
+	}
+}
+
+type watcher struct {
+	notifier   workspace.Notifier
+	watches    map[int64]func()
+	nextID     int64
+	registered bool
+	mu         sync.Mutex
+}
+
+func (w *watcher) watch(reevaluate func()) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.registered {
+		w.notifier.AddUpdateListener(w.onUpdate)
+		w.registered = true
+	}
+
+	id := w.nextID
+	w.nextID++
+	w.watches[id] = reevaluate
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watches, id)
+	}
+}
+
+func (w *watcher) onUpdate(oldWorkspace workspace.Workspace, newWorkspace workspace.Workspace, change workspace.Change) {
+	w.mu.Lock()
+	watches := make([]func(), 0, len(w.watches))
+	for _, it := range w.watches {
+		watches = append(watches, it)
+	}
+	w.mu.Unlock()
+
+	for _, reevaluate := range watches {
+		w.invoke(reevaluate)
+	}
+}
+
+func (w *watcher) invoke(reevaluate func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Unexpected panic in watch listener: %v", r)
+		}
+	}()
+	reevaluate()
+}
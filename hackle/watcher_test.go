@@ -0,0 +1,44 @@
+package hackle
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_watcher(t *testing.T) {
+
+	t.Run("register update listener once", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		sut := newWatcher(notifier)
+
+		sut.watch(func() {})
+		sut.watch(func() {})
+
+		assert.Equal(t, 1, len(notifier.listeners))
+	})
+
+	t.Run("isolate panic", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		sut := newWatcher(notifier)
+
+		count := 0
+		sut.watch(func() { panic("watch panic") })
+		sut.watch(func() { count++ })
+		notifier.update(nil, nil)
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		sut := newWatcher(notifier)
+
+		count := 0
+		cancel := sut.watch(func() { count++ })
+		notifier.update(nil, nil)
+		cancel()
+		notifier.update(nil, nil)
+
+		assert.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,15 @@
+package hackle
+
+import "github.com/hackle-io/hackle-go-sdk/hackle/internal/workspace"
+
+type WorkspaceChange = workspace.Change
+type ExperimentChange = workspace.ExperimentChange
+type SegmentChange = workspace.SegmentChange
+type RemoteConfigParameterChange = workspace.RemoteConfigParameterChange
+type ChangeType = workspace.ChangeType
+
+const (
+	ChangeTypeAdded    = workspace.ChangeTypeAdded
+	ChangeTypeRemoved  = workspace.ChangeTypeRemoved
+	ChangeTypeModified = workspace.ChangeTypeModified
+)